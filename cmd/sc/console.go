@@ -17,13 +17,10 @@ limitations under the License.
 package main
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"path"
 	"strings"
 	"sync"
@@ -132,56 +129,35 @@ var serverConsoleCmd = &cobra.Command{
 		wg := &sync.WaitGroup{}
 
 		for _, srvCfg := range servers {
-			cmd, stdout, stderr, err := server.Logs(ctx, srvCfg, 0*time.Millisecond, 10, true)
+			lines, err := server.LogStream(ctx, srvCfg, server.LogOptions{
+				Tail:   10,
+				Follow: true,
+			})
 			if err != nil {
-				return err
-			}
-			if stdout == nil || stderr == nil {
-				return fmt.Errorf("unable to get server container logs. server.Logs returned nil body. %+v", err)
+				return fmt.Errorf("unable to get server container logs. %+v", err)
 			}
 
-			wg.Add(3)
-			go func(serverName string, stream io.ReadCloser) {
+			wg.Add(1)
+			go func() {
 				defer wg.Done()
-				defer stream.Close()
-				scanner := bufio.NewScanner(stream)
-				for scanner.Scan() {
-					msg := scanner.Text()
-					if strings.Contains(msg, "srcds_controller_check") {
+				for line := range lines {
+					if strings.Contains(line.Text, "srcds_controller_check") {
 						continue
 					}
-					if len(servers) > 1 {
-						msg = fmt.Sprintf("%s: %s", serverName, msg)
+					if line.Stream == server.StreamStderr && !viper.GetBool("debug") {
+						continue
 					}
-					outChan <- msg
-				}
-				if scanner.Err() != nil {
-					log.Error(scanner.Err())
-					return
-				}
-			}(srvCfg.Server.Name, stdout)
-			go func(serverName string, stream io.ReadCloser) {
-				defer wg.Done()
-				defer stream.Close()
-				scanner := bufio.NewScanner(stream)
-				for scanner.Scan() {
-					msg := scanner.Text()
+					msg := line.Text
 					if len(servers) > 1 {
-						msg = fmt.Sprintf("%s: %s", serverName, msg)
+						msg = fmt.Sprintf("%s: %s", line.Server, msg)
 					}
-					if viper.GetBool("debug") {
-						outChan <- msg
+					select {
+					case outChan <- msg:
+					case <-ctx.Done():
+						return
 					}
 				}
-				if scanner.Err() != nil {
-					log.Error(scanner.Err())
-					return
-				}
-			}(srvCfg.Server.Name, stderr)
-			go func(c *exec.Cmd) {
-				defer wg.Done()
-				c.Wait()
-			}(cmd)
+			}()
 		}
 
 		wg.Add(1)
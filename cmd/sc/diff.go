@@ -0,0 +1,163 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/server"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// serverDiffCmd show the differences between the server containers and their config
+var serverDiffCmd = &cobra.Command{
+	Use:               "diff",
+	Short:             "Show differences between one or more server containers and their config",
+	PersistentPreRunE: initDockerCli,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		servers, err := checkServers(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		recreate, err := cmd.Flags().GetBool("recreate")
+		if err != nil {
+			return err
+		}
+		timeout, err := cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			return err
+		}
+		// Used by server.Stop
+		viper.Set("timeout", timeout)
+
+		errorOccured := false
+		mutex := sync.Mutex{}
+		diffs := map[string]*server.SpecDiff{}
+		wg := sync.WaitGroup{}
+		for _, serverCfg := range servers {
+			wg.Add(1)
+			go func(cfg *config.Config) {
+				defer wg.Done()
+				diff, err := server.Diff(cfg)
+				if err != nil {
+					log.Errorf("failed to diff server %s container. %+v", cfg.Server.Name, err)
+					errorOccured = true
+					return
+				}
+				mutex.Lock()
+				diffs[cfg.Server.Name] = diff
+				mutex.Unlock()
+			}(serverCfg)
+		}
+		wg.Wait()
+
+		names := []string{}
+		for name := range diffs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		w := tabwriter.NewWriter(os.Stdout, 1, 0, 1, ' ', tabwriter.Debug)
+		fmt.Fprintln(w, "Server\tField\tCurrent\tDesired")
+		for _, name := range names {
+			diff := diffs[name]
+			switch {
+			case !diff.Exists:
+				fmt.Fprintf(w, "%s\t-\tno container\t-\n", name)
+			case !diff.Drifted:
+				fmt.Fprintf(w, "%s\t-\tin sync\t-\n", name)
+			case diff.CurrentHash == "":
+				fmt.Fprintf(w, "%s\tspec hash\tnone (created by an older version)\t%.12s\n", name, diff.DesiredHash)
+			case len(diff.Changes) == 0:
+				fmt.Fprintf(w, "%s\tspec hash\t%.12s\t%.12s\n", name, diff.CurrentHash, diff.DesiredHash)
+			}
+			if !diff.Exists {
+				continue
+			}
+			for _, change := range diff.Changes {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, change.Field, orDash(change.Current), orDash(change.Desired))
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		if recreate {
+			for _, serverCfg := range servers {
+				diff, ok := diffs[serverCfg.Server.Name]
+				if !ok || !diff.Exists || !diff.Drifted {
+					continue
+				}
+				wg.Add(1)
+				go func(cfg *config.Config, running bool) {
+					defer wg.Done()
+					if err := recreateServer(cfg, running); err != nil {
+						log.Errorf("failed to recreate server %s container. %+v", cfg.Server.Name, err)
+						errorOccured = true
+					}
+				}(serverCfg, diff.Running)
+			}
+			wg.Wait()
+		}
+
+		if errorOccured {
+			return fmt.Errorf("error when diffing servers")
+		}
+		return nil
+	},
+}
+
+func init() {
+	serverDiffCmd.Flags().Bool("recreate", false, "Recreate the containers of the servers which don't match their config (running servers are restarted)")
+	serverDiffCmd.Flags().DurationP("timeout", "t", 4*time.Second, "Server stop timeout before kill will be triggered")
+
+	rootCmd.AddCommand(serverDiffCmd)
+}
+
+// recreateServer remove the server container and start it again when it has
+// been running
+func recreateServer(cfg *config.Config, running bool) error {
+	if running {
+		if err := server.Stop(cfg); err != nil {
+			return err
+		}
+	}
+	if err := server.Remove(cfg); err != nil {
+		return err
+	}
+	if !running {
+		log.Infof("removed server %s container, it is recreated on the next start", cfg.Server.Name)
+		return nil
+	}
+	return server.Start(cfg)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/checker"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// serverHealthCmd show the checker status of the servers.
+var serverHealthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Show the checker status of one or more servers (default all servers)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter := map[string]bool{}
+		if len(args) > 0 || viper.GetBool(AllServers) {
+			servers, err := checkServers(cmd, args)
+			if err != nil {
+				return err
+			}
+			for _, srv := range servers {
+				filter[srv.Server.Name] = true
+			}
+		}
+
+		statuses, err := checker.NewClient(viper.GetString("checker-address")).Status()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 1, 0, 1, ' ', tabwriter.Debug)
+		fmt.Fprintln(w, "Server\tCheck\tStatus\tFailures\tWindow\tLast Run\tNext Run\tLast Action\tMessage")
+		for _, srvStatus := range statuses {
+			if len(filter) > 0 && !filter[srvStatus.Name] {
+				continue
+			}
+			if srvStatus.Quarantine != nil {
+				fmt.Fprintf(w, "%s\t-\tQUARANTINED\t-\t-\t-\t-\t-\t%s\n", srvStatus.Name, srvStatus.Quarantine.Reason)
+			}
+			if srvStatus.Silenced != "" {
+				fmt.Fprintf(w, "%s\t-\tSILENCED\t-\t-\t-\t-\t-\t%s\n", srvStatus.Name, srvStatus.Silenced)
+			}
+			if srvStatus.Queued != nil {
+				state := "RESTART QUEUED"
+				if srvStatus.Queued.Running {
+					state = "RESTARTING"
+				}
+				fmt.Fprintf(w, "%s\t-\t%s\t-\t-\t%s\t-\t%s\t%s\n", srvStatus.Name, state, formatTime(srvStatus.Queued.Queued), srvStatus.Queued.Action, srvStatus.Queued.Reason)
+			}
+			if srvStatus.Disabled != "" {
+				fmt.Fprintf(w, "%s\t-\tDISABLED\t-\t-\t-\t-\t-\t%s\n", srvStatus.Name, srvStatus.Disabled)
+				continue
+			}
+			for _, check := range srvStatus.Checks {
+				status := "-"
+				message := ""
+				if check.LastResult != nil {
+					status = check.LastResult.Status.String()
+					message = check.LastResult.Message
+				}
+				if check.GracePeriod {
+					status += " (grace)"
+				}
+
+				failures := "0"
+				window := "-"
+				if check.Counter != nil {
+					failures = fmt.Sprintf("%d", check.Counter.Count)
+					window = formatDuration(check.Counter.LastTime.Sub(check.Counter.FirstTime))
+				}
+				if check.Limit != nil {
+					if check.Limit.Count != 0 {
+						failures += fmt.Sprintf("/%d", check.Limit.Count)
+					}
+					if check.Limit.After != 0 {
+						window += "/" + formatDuration(check.Limit.After)
+					}
+				}
+
+				nextRun := check.NextRun
+				if nextRun.IsZero() {
+					nextRun = srvStatus.NextRun
+				}
+
+				lastAction := "-"
+				if check.LastAction != nil {
+					lastAction = fmt.Sprintf("%v (%s ago)", check.LastAction.Actions, formatDuration(time.Since(check.LastAction.Time)))
+					if len(check.LastAction.Errors) > 0 {
+						failed := []string{}
+						for action := range check.LastAction.Errors {
+							failed = append(failed, action)
+						}
+						sort.Strings(failed)
+						lastAction += fmt.Sprintf(" failed: %s", strings.Join(failed, ", "))
+					}
+				}
+
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					srvStatus.Name,
+					check.ID,
+					status,
+					failures,
+					window,
+					formatTime(check.LastRun),
+					formatTime(nextRun),
+					lastAction,
+					message,
+				)
+			}
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serverHealthCmd)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("15:04:05")
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/actions"
+	"github.com/galexrt/srcds_controller/pkg/actions/restart"
+	"github.com/galexrt/srcds_controller/pkg/checker"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/notify"
+	"github.com/galexrt/srcds_controller/pkg/server"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// serverImageCmd represents the server image subcommand
+var serverImageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "server container image management subcommand section",
+}
+
+// serverImagePullCmd pull the images of the servers and show which are outdated.
+var serverImagePullCmd = &cobra.Command{
+	Use:               "pull",
+	Short:             "Pull the images of one or more servers and show which servers run an outdated image",
+	PersistentPreRunE: initDockerCli,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		servers, err := checkServers(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		errorOccured := false
+		pulled := map[string]bool{}
+		for _, serverCfg := range servers {
+			image := *serverCfg.Docker.Image
+			if pulled[image] {
+				continue
+			}
+			pulled[image] = true
+			if err := server.PullImage(ctx, image); err != nil {
+				log.Error(err)
+				errorOccured = true
+			}
+		}
+
+		statuses, err := imageStatuses(ctx, servers)
+		if err != nil {
+			return err
+		}
+		if err := printImageStatuses(statuses); err != nil {
+			return err
+		}
+
+		if errorOccured {
+			return fmt.Errorf("error when pulling images")
+		}
+		return nil
+	},
+}
+
+// serverImageRolloutCmd recreate the servers which run an outdated image one by one.
+var serverImageRolloutCmd = &cobra.Command{
+	Use:               "rollout",
+	Short:             "Recreate one or more servers running an outdated image one by one, with a countdown and waiting for each server to be healthy",
+	PersistentPreRunE: initDockerCli,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		servers, err := checkServers(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		countdown, err := cmd.Flags().GetDuration("countdown")
+		if err != nil {
+			return err
+		}
+		announce, err := cmd.Flags().GetString("announce")
+		if err != nil {
+			return err
+		}
+		healthTimeout, err := cmd.Flags().GetDuration("health-timeout")
+		if err != nil {
+			return err
+		}
+		timeout, err := cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			return err
+		}
+		// Used by server.Stop and server.Restart
+		viper.Set("timeout", timeout)
+		viper.Set("remove", true)
+
+		niceRestart, err := restart.NewNice(config.CheckOpts{
+			"countdown": countdown.String(),
+			"announce":  announce,
+		})
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		statuses, err := imageStatuses(ctx, servers)
+		if err != nil {
+			return err
+		}
+
+		outdated := []string{}
+		for i, serverCfg := range servers {
+			status := statuses[i]
+			if !status.Outdated() {
+				log.Debugf("server %s runs the latest image, skipping", serverCfg.Server.Name)
+				continue
+			}
+			outdated = append(outdated, serverCfg.Server.Name)
+
+			startedAt, err := server.StartedAt(serverCfg)
+			if err != nil {
+				return err
+			}
+			if startedAt.IsZero() {
+				// Stopped servers get the new image on their next start
+				log.Infof("server %s is not running, removing its container", serverCfg.Server.Name)
+				if err := server.Remove(serverCfg); err != nil {
+					return err
+				}
+				continue
+			}
+
+			log.Infof("rolling out image %s to server %s", status.Image, serverCfg.Server.Name)
+			err = niceRestart.Run(ctx, actions.Event{
+				Server: serverCfg,
+				Time:   time.Now(),
+			})
+			notifyManual(serverCfg, notify.EventServerRestart, "restarted for image rollout", err)
+			if err != nil {
+				return fmt.Errorf("failed to restart server %s. %w", serverCfg.Server.Name, err)
+			}
+
+			// Only check results of the new container count, the old one has
+			// been checked during the countdown
+			restartedAt, err := server.StartedAt(serverCfg)
+			if err != nil || restartedAt.IsZero() {
+				restartedAt = time.Now()
+			}
+
+			if healthTimeout > 0 {
+				if err := waitServerHealthy(serverCfg, restartedAt, healthTimeout); err != nil {
+					return fmt.Errorf("stopping rollout, %w", err)
+				}
+			}
+		}
+
+		if len(outdated) == 0 {
+			log.Info("all servers run the latest image, pull new images with `sc image pull`")
+			return nil
+		}
+		log.Infof("rolled out image to servers: %s", strings.Join(outdated, ", "))
+		return nil
+	},
+}
+
+func init() {
+	serverImageRolloutCmd.Flags().Duration("countdown", 2*time.Minute, "Countdown announced on the server before it is restarted")
+	serverImageRolloutCmd.Flags().String("announce", "say Server restart for an update in %d second(s)!", "Command template to announce the remaining seconds of the countdown")
+	serverImageRolloutCmd.Flags().Duration("health-timeout", 10*time.Minute, "How long to wait for a server to be healthy before rolling out to the next server (0 to not wait)")
+	serverImageRolloutCmd.Flags().DurationP("timeout", "t", 4*time.Second, "Server stop timeout before kill will be triggered")
+
+	serverImageCmd.AddCommand(serverImagePullCmd)
+	serverImageCmd.AddCommand(serverImageRolloutCmd)
+	rootCmd.AddCommand(serverImageCmd)
+}
+
+func imageStatuses(ctx context.Context, servers []*config.Config) ([]*server.ImageStatus, error) {
+	statuses := []*server.ImageStatus{}
+	for _, serverCfg := range servers {
+		status, err := server.GetImageStatus(ctx, serverCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get image status of server %s. %w", serverCfg.Server.Name, err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func printImageStatuses(statuses []*server.ImageStatus) error {
+	w := tabwriter.NewWriter(os.Stdout, 1, 0, 1, ' ', tabwriter.Debug)
+	fmt.Fprintln(w, "Server\tImage\tRunning\tLatest\tStatus")
+	for _, status := range statuses {
+		state := "up to date"
+		switch {
+		case !status.Exists:
+			state = "no container"
+		case status.Latest == "":
+			state = "image not on machine"
+		case status.Outdated():
+			state = "outdated"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			status.Server,
+			status.Image,
+			shortDigest(status.Current),
+			shortDigest(status.Latest),
+			state,
+		)
+	}
+	return w.Flush()
+}
+
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if digest == "" {
+		return "-"
+	}
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
+
+// waitServerHealthy wait for all checks of the server run after the given
+// time to be healthy, falls back to the container running when the checker
+// isn't reachable
+func waitServerHealthy(serverCfg *config.Config, since time.Time, timeout time.Duration) error {
+	client := checker.NewClient(viper.GetString("checker-address"))
+	deadline := time.Now().Add(timeout)
+	checkerWarned := false
+	for time.Now().Before(deadline) {
+		time.Sleep(5 * time.Second)
+
+		statuses, err := client.Status()
+		if err != nil {
+			if !checkerWarned {
+				log.Warnf("checker not reachable, only waiting for server %s container to run. %+v", serverCfg.Server.Name, err)
+				checkerWarned = true
+			}
+			startedAt, err := server.StartedAt(serverCfg)
+			if err != nil {
+				return err
+			}
+			if !startedAt.IsZero() {
+				return nil
+			}
+			continue
+		}
+
+		healthy, reason := serverHealthy(statuses, serverCfg.Server.Name, since)
+		if healthy {
+			log.Infof("server %s is healthy", serverCfg.Server.Name)
+			return nil
+		}
+		log.Infof("waiting for server %s to be healthy, %s", serverCfg.Server.Name, reason)
+	}
+	return fmt.Errorf("server %s not healthy within %s", serverCfg.Server.Name, timeout)
+}
+
+func serverHealthy(statuses []*checker.ServerStatus, serverName string, since time.Time) (bool, string) {
+	for _, srvStatus := range statuses {
+		if srvStatus.Name != serverName {
+			continue
+		}
+		if srvStatus.Quarantine != nil {
+			return false, "server is quarantined"
+		}
+		if len(srvStatus.Checks) == 0 {
+			return false, "no checks running"
+		}
+		for _, check := range srvStatus.Checks {
+			if check.LastResult == nil || !check.LastRun.After(since) {
+				return false, fmt.Sprintf("check %s not run yet", check.ID)
+			}
+			if !check.LastResult.Healthy() {
+				return false, fmt.Sprintf("check %s is %s", check.ID, check.LastResult.Status)
+			}
+		}
+		return true, ""
+	}
+	return false, "server not known to the checker"
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+
+	"github.com/galexrt/srcds_controller/pkg/checker"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// serverJournalCmd show the checker decision journal.
+var serverJournalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "Show the checker decision journal of one or more servers (default all servers)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serverNames := []string{}
+		if len(args) > 0 || viper.GetBool(AllServers) {
+			servers, err := checkServers(cmd, args)
+			if err != nil {
+				return err
+			}
+			for _, srv := range servers {
+				serverNames = append(serverNames, srv.Server.Name)
+			}
+		}
+
+		tail, err := cmd.Flags().GetInt("tail")
+		if err != nil {
+			return err
+		}
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return err
+		}
+
+		entries, err := checker.NewClient(viper.GetString("checker-address")).Journal(serverNames, tail)
+		if err != nil {
+			return err
+		}
+		return checker.WriteJournal(os.Stdout, entries, asJSON)
+	},
+}
+
+func init() {
+	serverJournalCmd.Flags().Int("tail", 50, "number of entries to show (0 for all)")
+	serverJournalCmd.Flags().Bool("json", false, "output the entries as JSON lines")
+
+	rootCmd.AddCommand(serverJournalCmd)
+}
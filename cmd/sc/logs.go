@@ -17,10 +17,8 @@ limitations under the License.
 package main
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"io"
 	"strings"
 	"sync"
 	"time"
@@ -41,89 +39,51 @@ var serverLogsCmd = &cobra.Command{
 			return err
 		}
 
-		var wg sync.WaitGroup
-		errors := make(chan error)
-		outChan := make(chan string)
-		doneCh := make(chan struct{})
-
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
+		opts := server.LogOptions{
+			Since:  viper.GetDuration("since"),
+			Tail:   viper.GetInt("tail"),
+			Follow: viper.GetBool("follow"),
+		}
+
+		var wg sync.WaitGroup
+		outChan := make(chan string)
 		for _, serverCfg := range servers {
-			cmd, stdin, stderr, err := server.Logs(ctx, serverCfg, viper.GetDuration("since"), viper.GetInt("tail"), viper.GetBool("follow"))
+			lines, err := server.LogStream(ctx, serverCfg, opts)
 			if err != nil {
 				return err
 			}
-			if stdin == nil || stderr == nil {
-				return fmt.Errorf("server.Logs returned no response. something is wrong")
-			}
 
-			wg.Add(3)
+			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				cmd.Wait()
-			}()
-			go func(serverName string, stream io.ReadCloser) {
-				defer wg.Done()
-				defer stdin.Close()
-
-				scanner := bufio.NewScanner(stream)
-				for scanner.Scan() {
-					msg := scanner.Text()
-					if strings.Contains(msg, "srcds_controller_check") {
+				for line := range lines {
+					if strings.Contains(line.Text, "srcds_controller_check") {
 						continue
 					}
-					msg = colorMessage(msg)
-					if len(servers) > 1 {
-						msg = fmt.Sprintf("%s: %s", serverName, msg)
-					}
-					outChan <- msg
-				}
-				if scanner.Err() != nil {
-					errors <- scanner.Err()
-					return
-				}
-			}(serverCfg.Server.Name, stdin)
-			go func(serverName string, stream io.ReadCloser) {
-				defer wg.Done()
-				defer stderr.Close()
-
-				scanner := bufio.NewScanner(stream)
-				for scanner.Scan() {
-					msg := scanner.Text()
-					if strings.Contains(msg, "srcds_controller_check") {
+					if line.Stream == server.StreamStderr && !viper.GetBool("debug") {
 						continue
 					}
-					msg = colorMessage(msg)
+					msg := colorMessage(line.Text)
 					if len(servers) > 1 {
-						msg = fmt.Sprintf("%s: %s", serverName, msg)
+						msg = fmt.Sprintf("%s: %s", line.Server, msg)
 					}
-					if viper.GetBool("debug") {
-						outChan <- msg
-					}
-				}
-				if scanner.Err() != nil {
-					errors <- scanner.Err()
-					return
+					outChan <- msg
 				}
-			}(serverCfg.Server.Name, stderr)
+			}()
 		}
 
 		go func() {
 			wg.Wait()
-			close(doneCh)
+			close(outChan)
 		}()
 
-		for {
-			select {
-			case out := <-outChan:
-				fmt.Println(out)
-			case erro := <-errors:
-				return erro
-			case <-doneCh:
-				return nil
-			}
+		for out := range outChan {
+			fmt.Println(out)
 		}
+		return nil
 	},
 }
 
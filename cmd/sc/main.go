@@ -17,11 +17,15 @@ limitations under the License.
 package main
 
 import (
-	"io/ioutil"
 	"os"
 	"path"
 
 	"github.com/docker/docker/client"
+	"github.com/galexrt/srcds_controller/pkg/checker"
+	// Register the checks so the server configs checks are validated
+	_ "github.com/galexrt/srcds_controller/pkg/checks/actioreactio"
+	_ "github.com/galexrt/srcds_controller/pkg/checks/exec"
+	_ "github.com/galexrt/srcds_controller/pkg/checks/rcon"
 	"github.com/galexrt/srcds_controller/pkg/config"
 	"github.com/galexrt/srcds_controller/pkg/server"
 	"github.com/galexrt/srcds_controller/pkg/userconfig"
@@ -29,7 +33,6 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	yaml "gopkg.in/yaml.v2"
 )
 
 const (
@@ -75,10 +78,14 @@ func init() {
 	rootCmd.PersistentFlags().Bool("debug", false, "If debug info should be shown")
 	rootCmd.PersistentFlags().BoolP(AllServers, "a", false, "If all servers should be used")
 	rootCmd.PersistentFlags().BoolP("remove", "r", false, "Remove the server container before starting if it exists (if applicable to the subcommand used)")
+	rootCmd.PersistentFlags().String("checker-address", checker.DefaultAPIAddress, "srcds_controller checker API address, either HOST:PORT or unix:PATH")
+	rootCmd.PersistentFlags().String("checker-socket", checker.DefaultAPISocket, "srcds_controller checker API unix socket used for changes (e.g., clearing a quarantine, maintenance silences)")
 
 	viper.BindPFlag("debug", rootCmd.PersistentFlags().Lookup("debug"))
 	viper.BindPFlag(AllServers, rootCmd.PersistentFlags().Lookup(AllServers))
 	viper.BindPFlag("remove", rootCmd.PersistentFlags().Lookup("remove"))
+	viper.BindPFlag("checker-address", rootCmd.PersistentFlags().Lookup("checker-address"))
+	viper.BindPFlag("checker-socket", rootCmd.PersistentFlags().Lookup("checker-socket"))
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -96,36 +103,9 @@ func initConfig() {
 		cfgFile = path.Join(home, ".srcds_controller.yaml")
 	}
 
-	// Load global config
-	globalCfg := &config.GlobalConfig{}
-	if _, err := os.Stat(globalCfgFile); err == nil {
-		out, err := ioutil.ReadFile(globalCfgFile)
-		if err != nil {
-			log.Fatal(err)
-		}
-		if err = yaml.Unmarshal(out, globalCfg); err != nil {
-			log.Fatal(err)
-		}
-	}
-
-	userCfg := &userconfig.UserConfig{}
-	cfgs := &userconfig.Config{
-		Servers: map[string]*config.Config{},
-	}
-
-	if _, err := os.Stat(cfgFile); err == nil {
-		out, err := ioutil.ReadFile(cfgFile)
-		if err != nil {
-			log.Fatal(err)
-		}
-		if err = yaml.Unmarshal(out, userCfg); err != nil {
-			log.Fatal(err)
-		}
-		if err = userCfg.Load(globalCfg, cfgs); err != nil {
-			log.Fatal(err)
-		}
-	} else {
-		log.Fatal("no config found in home dir nor specified by flag")
+	_, cfgs, err := userconfig.LoadFiles(cfgFile, globalCfgFile)
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	userconfig.Cfg = cfgs
@@ -0,0 +1,146 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"text/tabwriter"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/checker"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// serverMaintenanceCmd show the maintenance silences of the checker.
+var serverMaintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Show the active maintenance silences, during which the checker doesn't run any actions",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		silences, err := checker.NewClient(viper.GetString("checker-address")).Silences()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 1, 0, 1, ' ', tabwriter.Debug)
+		fmt.Fprintln(w, "Server\tStart\tEnd\tRemaining\tCreated By\tReason")
+		for _, silence := range silences {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				silence.Server,
+				silence.Start.Local().Format(time.RFC3339),
+				silence.End.Local().Format(time.RFC3339),
+				formatDuration(time.Until(silence.End)),
+				silence.CreatedBy,
+				silence.Reason,
+			)
+		}
+		return w.Flush()
+	},
+}
+
+// serverMaintenanceStartCmd silence servers for maintenance.
+var serverMaintenanceStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Silence one or more servers for maintenance",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		servers, err := checkServers(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		duration, err := cmd.Flags().GetDuration("for")
+		if err != nil {
+			return err
+		}
+		if duration <= 0 {
+			return fmt.Errorf("maintenance duration must be greater than 0")
+		}
+		reason, err := cmd.Flags().GetString("reason")
+		if err != nil {
+			return err
+		}
+		username := "unknown"
+		if u, err := user.Current(); err == nil {
+			username = u.Username
+		}
+
+		// Changes are only allowed over the checker API socket
+		client := checker.NewClient("unix:" + viper.GetString("checker-socket"))
+		errorOccured := false
+		now := time.Now()
+		for _, serverCfg := range servers {
+			if err := client.AddSilence(&checker.Silence{
+				Server:    serverCfg.Server.Name,
+				Reason:    reason,
+				CreatedBy: username,
+				Start:     now,
+				End:       now.Add(duration),
+			}); err != nil {
+				log.Errorf("failed to start maintenance of server %s. %+v", serverCfg.Server.Name, err)
+				errorOccured = true
+				continue
+			}
+			log.Infof("started maintenance of server %s for %s", serverCfg.Server.Name, duration)
+		}
+
+		if errorOccured {
+			return fmt.Errorf("error when starting maintenance of servers")
+		}
+		return nil
+	},
+}
+
+// serverMaintenanceStopCmd remove the maintenance silence of servers.
+var serverMaintenanceStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "End the maintenance silence of one or more servers",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		servers, err := checkServers(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		// Changes are only allowed over the checker API socket
+		client := checker.NewClient("unix:" + viper.GetString("checker-socket"))
+		errorOccured := false
+		for _, serverCfg := range servers {
+			if err := client.RemoveSilence(serverCfg.Server.Name); err != nil {
+				log.Errorf("failed to stop maintenance of server %s. %+v", serverCfg.Server.Name, err)
+				errorOccured = true
+				continue
+			}
+			log.Infof("stopped maintenance of server %s", serverCfg.Server.Name)
+		}
+
+		if errorOccured {
+			return fmt.Errorf("error when stopping maintenance of servers")
+		}
+		return nil
+	},
+}
+
+func init() {
+	serverMaintenanceStartCmd.Flags().Duration("for", 1*time.Hour, "Duration of the maintenance, the silence expires afterwards")
+	serverMaintenanceStartCmd.Flags().String("reason", "maintenance", "Reason for the maintenance")
+
+	serverMaintenanceCmd.AddCommand(serverMaintenanceStartCmd)
+	serverMaintenanceCmd.AddCommand(serverMaintenanceStopCmd)
+	rootCmd.AddCommand(serverMaintenanceCmd)
+}
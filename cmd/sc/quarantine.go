@@ -0,0 +1,88 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/checker"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// serverQuarantineCmd show the servers quarantined by the checker.
+var serverQuarantineCmd = &cobra.Command{
+	Use:   "quarantine",
+	Short: "Show the servers quarantined by the checker after using up their restart budget",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		quarantines, err := checker.NewClient(viper.GetString("checker-address")).Quarantines()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 1, 0, 1, ' ', tabwriter.Debug)
+		fmt.Fprintln(w, "Server\tSince\tRestarts\tReason")
+		for _, q := range quarantines {
+			fmt.Fprintf(w, "%s\t%s (%s ago)\t%d\t%s\n",
+				q.Server,
+				q.Since.Local().Format(time.RFC3339),
+				formatDuration(time.Since(q.Since)),
+				len(q.Restarts),
+				q.Reason,
+			)
+		}
+		return w.Flush()
+	},
+}
+
+// serverQuarantineClearCmd clear the quarantine of servers.
+var serverQuarantineClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Clear the quarantine and restart history of one or more servers",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		servers, err := checkServers(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		// Changes are only allowed over the checker API socket
+		client := checker.NewClient("unix:" + viper.GetString("checker-socket"))
+		errorOccured := false
+		for _, serverCfg := range servers {
+			if err := client.ClearQuarantine(serverCfg.Server.Name); err != nil {
+				log.Errorf("failed to clear quarantine of server %s. %+v", serverCfg.Server.Name, err)
+				errorOccured = true
+				continue
+			}
+			log.Infof("cleared quarantine of server %s", serverCfg.Server.Name)
+		}
+
+		if errorOccured {
+			return fmt.Errorf("error when clearing quarantine of servers")
+		}
+		return nil
+	},
+}
+
+func init() {
+	serverQuarantineCmd.AddCommand(serverQuarantineClearCmd)
+	rootCmd.AddCommand(serverQuarantineCmd)
+}
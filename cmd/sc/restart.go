@@ -22,7 +22,9 @@ import (
 	"time"
 
 	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/notify"
 	"github.com/galexrt/srcds_controller/pkg/server"
+	"github.com/galexrt/srcds_controller/pkg/statuspage"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -39,16 +41,26 @@ var serverRestartCmd = &cobra.Command{
 			return err
 		}
 
+		incident, err := cmd.Flags().GetString("incident")
+		if err != nil {
+			return err
+		}
+
 		errorOccured := false
 		wg := sync.WaitGroup{}
 		for _, serverCfg := range servers {
 			wg.Add(1)
 			go func(cfg *config.Config) {
 				defer wg.Done()
-				if err := server.Restart(cfg); err != nil {
+				if incident != "" {
+					openIncident(cfg, incident, statuspage.ComponentPartialOutage)
+				}
+				err := server.Restart(cfg)
+				if err != nil {
 					log.Errorf("error during container restart. %+v", err)
 					errorOccured = true
 				}
+				notifyManual(cfg, notify.EventServerRestart, "restarted", err)
 			}(serverCfg)
 		}
 		wg.Wait()
@@ -61,6 +73,7 @@ var serverRestartCmd = &cobra.Command{
 
 func init() {
 	serverRestartCmd.PersistentFlags().DurationP("timeout", "t", 4*time.Second, "Server stop timeout before kill will be triggered")
+	serverRestartCmd.Flags().String("incident", "", "Open a status page incident with this message for the servers, resolved by the checker once the servers are healthy again")
 	viper.BindPFlag("timeout", serverRestartCmd.PersistentFlags().Lookup("timeout"))
 
 	rootCmd.AddCommand(serverRestartCmd)
@@ -21,6 +21,7 @@ import (
 	"sync"
 
 	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/notify"
 	"github.com/galexrt/srcds_controller/pkg/server"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -51,10 +52,12 @@ var serverStartCmd = &cobra.Command{
 					}
 				}
 
-				if err := server.Start(cfg); err != nil {
+				err := server.Start(cfg)
+				if err != nil {
 					log.Errorf("error during server %s start. %+v", cfg.Server.Name, err)
 					errorOccured = true
 				}
+				notifyManual(cfg, notify.EventServerStart, "started", err)
 			}(serverCfg)
 		}
 		wg.Wait()
@@ -22,7 +22,9 @@ import (
 	"time"
 
 	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/notify"
 	"github.com/galexrt/srcds_controller/pkg/server"
+	"github.com/galexrt/srcds_controller/pkg/statuspage"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -39,17 +41,27 @@ var serverStopCmd = &cobra.Command{
 			return err
 		}
 
+		incident, err := cmd.Flags().GetString("incident")
+		if err != nil {
+			return err
+		}
+
 		errorOccured := false
 		wg := sync.WaitGroup{}
 		for _, serverCfg := range servers {
 			wg.Add(1)
 			go func(cfg *config.Config) {
 				defer wg.Done()
+				if incident != "" {
+					openIncident(cfg, incident, statuspage.ComponentMajorOutage)
+				}
 
-				if err := server.Stop(cfg); err != nil {
+				err := server.Stop(cfg)
+				if err != nil {
 					log.Errorf("error during container stop. %+v", err)
 					errorOccured = true
 				}
+				notifyManual(cfg, notify.EventServerStop, "stopped", err)
 
 				if viper.GetBool("remove") {
 					if err := server.Remove(cfg); err != nil {
@@ -69,6 +81,7 @@ var serverStopCmd = &cobra.Command{
 
 func init() {
 	serverStopCmd.PersistentFlags().DurationP("timeout", "t", 15*time.Second, "Server stop timeout before kill will be triggered")
+	serverStopCmd.Flags().String("incident", "", "Open a status page incident with this message for the servers, resolved by the checker once the servers are healthy again")
 	viper.BindPFlag("timeout", serverStopCmd.PersistentFlags().Lookup("timeout"))
 
 	rootCmd.AddCommand(serverStopCmd)
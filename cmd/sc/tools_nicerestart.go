@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"sync"
@@ -51,113 +52,77 @@ var serverToolsNiceRestart = &cobra.Command{
 			viper.Set("remove", true)
 		}
 
-		duration := viper.GetDuration("duration")
-
-		errorOccured := false
+		for _, template := range []string{viper.GetString("announce-minutes"), viper.GetString("announce-seconds")} {
+			if err := server.CheckAnnounceTemplate(template); err != nil {
+				return err
+			}
+		}
 
+		countdown := &server.Countdown{
+			Duration:        viper.GetDuration("duration"),
+			AnnounceMinutes: viper.GetString("announce-minutes"),
+			AnnounceSeconds: viper.GetString("announce-seconds"),
+			Times:           map[int]bool{},
+		}
 		rawAnnounceTimes := viper.GetStringSlice("default-announce-times")
 		rawAnnounceTimes = append(rawAnnounceTimes, viper.GetStringSlice("additional-announce-times")...)
-
-		secsTotal := int(duration.Seconds())
-		secsRemaining := secsTotal
-
-		byMinuteAnnouncement := false
-		bySecondsAnnouncement := false
-		var announceTimes []string
 		for _, value := range rawAnnounceTimes {
-			if value == AnnounceEveryMinute {
-				byMinuteAnnouncement = true
-			} else if value == AnnounceEverySecond {
-				bySecondsAnnouncement = true
-			}
-			if value != "" {
-				announceTimes = append(announceTimes, value)
-			}
-		}
-
-		contains := func(s []string, e string) bool {
-			for _, a := range s {
-				if a == e {
-					return true
+			switch value {
+			case AnnounceEveryMinute:
+				countdown.EveryMinute = true
+			case AnnounceEverySecond:
+				countdown.EverySecond = true
+			default:
+				if secs, err := strconv.Atoi(value); err == nil {
+					countdown.Times[secs] = true
 				}
 			}
-			return false
 		}
 
-		timeLoggerCoolDown := 15
-	timeLoop:
-		for {
-			if secsRemaining <= 0 {
-				wg := sync.WaitGroup{}
-				for _, serverCfg := range servers {
-					wg.Add(1)
-					go func(cfg *config.Config) {
-						defer wg.Done()
-						stopCommands := viper.GetStringSlice("stop-commands")
-						log.Debugf("stop commands given: %+v", stopCommands)
-						if len(stopCommands) > 0 {
-							log.Infof("sending commands instead of stopping/restarting the server(s): %+v", stopCommands)
-							for _, command := range stopCommands {
-								if err := server.SendCommand(cfg, []string{command}); err != nil {
-									errorOccured = true
-								}
-							}
-							return
-						}
+		if err := countdown.Run(context.Background(), servers); err != nil {
+			return err
+		}
 
-						if err := server.Stop(cfg); err != nil {
-							log.Errorf("error during server stop. %+v", err)
+		errorOccured := false
+		wg := sync.WaitGroup{}
+		for _, serverCfg := range servers {
+			wg.Add(1)
+			go func(cfg *config.Config) {
+				defer wg.Done()
+				stopCommands := viper.GetStringSlice("stop-commands")
+				log.Debugf("stop commands given: %+v", stopCommands)
+				if len(stopCommands) > 0 {
+					log.Infof("sending commands instead of stopping/restarting the server(s): %+v", stopCommands)
+					for _, command := range stopCommands {
+						if err := server.SendCommand(cfg, []string{command}); err != nil {
 							errorOccured = true
 						}
-
-						if viper.GetBool("remove") {
-							if err := server.Remove(cfg); err != nil {
-								log.Errorf("error during server container removal. %+v", err)
-								errorOccured = true
-							}
-						}
-
-						if !viper.GetBool("stop-only") {
-							time.Sleep(500 * time.Millisecond)
-							if err := server.Start(cfg); err != nil {
-								log.Errorf("error during server start. %+v", err)
-								errorOccured = true
-							}
-						}
-					}(serverCfg)
+					}
+					return
 				}
-				wg.Wait()
-				break timeLoop
-			}
 
-			mins := float64(secsRemaining) / float64(60)
-			if byMinuteAnnouncement && mins == float64(int64(mins)) {
-				log.Info("countdown: another minute is over")
-				if viper.GetString("announce-minutes") != "" {
-					command := fmt.Sprintf(viper.GetString("announce-minutes"), int64(mins))
-					sendCommandInParallel(servers, command)
-				}
-			} else if contains(announceTimes, strconv.Itoa(secsRemaining)) {
-				log.Debug("countdown: need to announce")
-				if viper.GetString("announce-seconds") != "" {
-					command := fmt.Sprintf(viper.GetString("announce-seconds"), int64(secsRemaining))
-					sendCommandInParallel(servers, command)
+				if err := server.Stop(cfg); err != nil {
+					log.Errorf("error during server stop. %+v", err)
+					errorOccured = true
 				}
-			} else if bySecondsAnnouncement {
-				if viper.GetString("announce-seconds") != "" {
-					command := fmt.Sprintf(viper.GetString("announce-seconds"), int64(secsRemaining))
-					sendCommandInParallel(servers, command)
+
+				if viper.GetBool("remove") {
+					if err := server.Remove(cfg); err != nil {
+						log.Errorf("error during server container removal. %+v", err)
+						errorOccured = true
+					}
 				}
-			}
-			if timeLoggerCoolDown == 15 || timeLoggerCoolDown == 0 {
-				log.Infof("countdown: remaining: %d seconds, total: %d seconds", secsRemaining, secsTotal)
-				timeLoggerCoolDown = 15
-			}
-			timeLoggerCoolDown--
 
-			time.Sleep(1 * time.Second)
-			secsRemaining--
+				if !viper.GetBool("stop-only") {
+					time.Sleep(500 * time.Millisecond)
+					if err := server.Start(cfg); err != nil {
+						log.Errorf("error during server start. %+v", err)
+						errorOccured = true
+					}
+				}
+			}(serverCfg)
 		}
+		wg.Wait()
 
 		if errorOccured {
 			return fmt.Errorf("error when sending commands")
@@ -184,20 +149,3 @@ func init() {
 
 	serverToolsCmd.AddCommand(serverToolsNiceRestart)
 }
-
-func sendCommandInParallel(servers []*config.Config, command string) bool {
-	errorOccured := false
-	wg := sync.WaitGroup{}
-	for _, serverCfg := range servers {
-		wg.Add(1)
-		go func(cfg *config.Config) {
-			defer wg.Done()
-			if err := server.SendCommand(cfg, []string{command}); err != nil {
-				log.Errorf("error while sending command to server %s. %+v", cfg.Server.Name, err)
-				errorOccured = true
-			}
-		}(serverCfg)
-	}
-	wg.Wait()
-	return errorOccured
-}
@@ -17,12 +17,18 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
+	"os/user"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/galexrt/srcds_controller/pkg/checker"
 	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/notify"
+	"github.com/galexrt/srcds_controller/pkg/statuspage"
 	"github.com/galexrt/srcds_controller/pkg/userconfig"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -78,3 +84,33 @@ func colorMessage(msg string) string {
 
 	return msg
 }
+
+// notifyManual send a notification about a manual server lifecycle action,
+// the error of the action (if any) is added to the message
+func notifyManual(cfg *config.Config, eventType string, action string, actionErr error) {
+	username := "unknown"
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+
+	msg := fmt.Sprintf("server %s by %s via sc", action, username)
+	if actionErr != nil {
+		msg = fmt.Sprintf("server %s by %s via sc failed. %v", action, username, actionErr)
+	}
+	notify.Send(context.Background(), cfg, notify.Event{
+		Type:    eventType,
+		Message: msg,
+	})
+}
+
+// openIncident open a status page incident for the server and hand it over to
+// the checker, which resolves it once the server is healthy again
+func openIncident(cfg *config.Config, message string, componentStatus statuspage.ComponentStatus) {
+	id := statuspage.OpenIncident(cfg, message, statuspage.IncidentIdentified, componentStatus, nil)
+	if id == 0 {
+		return
+	}
+	if err := checker.NewClient("unix:"+viper.GetString("checker-socket")).TrackIncident(cfg.Server.Name, id); err != nil {
+		log.Warnf("failed to hand over status page incident %d of server %s to the checker, it has to be resolved on the status page. %+v", id, cfg.Server.Name, err)
+	}
+}
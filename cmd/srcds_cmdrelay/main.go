@@ -14,6 +14,10 @@ import (
 	"syscall"
 	"time"
 
+	// Register the checks so the server configs checks are validated
+	_ "github.com/galexrt/srcds_controller/pkg/checks/actioreactio"
+	_ "github.com/galexrt/srcds_controller/pkg/checks/exec"
+	_ "github.com/galexrt/srcds_controller/pkg/checks/rcon"
 	"github.com/galexrt/srcds_controller/pkg/config"
 	"github.com/galexrt/srcds_controller/pkg/userconfig"
 	"github.com/gin-gonic/gin"
@@ -20,19 +20,32 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path"
 	"sync"
 	"syscall"
+	"time"
 
+	homedir "github.com/mitchellh/go-homedir"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
-	// Import checks
 	"github.com/galexrt/go-rcon"
+
+	// Import actions and checks
+	_ "github.com/galexrt/srcds_controller/pkg/actions/console"
+	_ "github.com/galexrt/srcds_controller/pkg/actions/disable"
+	_ "github.com/galexrt/srcds_controller/pkg/actions/exec"
+	_ "github.com/galexrt/srcds_controller/pkg/actions/log"
+	_ "github.com/galexrt/srcds_controller/pkg/actions/restart"
+	_ "github.com/galexrt/srcds_controller/pkg/actions/stop"
+	_ "github.com/galexrt/srcds_controller/pkg/actions/webhook"
 	_ "github.com/galexrt/srcds_controller/pkg/checks/actioreactio"
+	_ "github.com/galexrt/srcds_controller/pkg/checks/exec"
 	_ "github.com/galexrt/srcds_controller/pkg/checks/rcon"
 
 	"github.com/galexrt/srcds_controller/pkg/checker"
+	"github.com/galexrt/srcds_controller/pkg/userconfig"
 )
 
 // checkerCmd represents the checker command
@@ -53,16 +66,84 @@ var checkerCmd = &cobra.Command{
 		}
 
 		stopCh := make(chan struct{})
-		sigCh := make(chan os.Signal)
+		sigCh := make(chan os.Signal, 1)
 		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 		log.Info("running checker")
 
+		chkr := checker.New()
+
+		var lock *checker.Lock
+		if lockFile := viper.GetString("lock-file"); lockFile != "" {
+			lock = checker.NewLock(lockFile)
+			locked, err := lock.TryLock()
+			if err != nil {
+				return fmt.Errorf("%w. Set a lock file path the checker user can write to with --lock-file (empty to disable the lock)", err)
+			}
+			if !locked {
+				if !viper.GetBool("standby") {
+					return fmt.Errorf("another checker (pid %s) is already running and holds the lock file %s, use --standby to wait for it to go away", lock.Holder(), lockFile)
+				}
+				log.Infof("another checker (pid %s) holds the lock file %s, running in standby", lock.Holder(), lockFile)
+			}
+			defer lock.Unlock()
+		}
+
+		var runErr error
 		wg := sync.WaitGroup{}
+		// startServices start the status API and the config watcher, only
+		// called once the lock is held so a checker in standby doesn't take
+		// over the API address of the active checker
+		startServices := func() {
+			addresses := []string{viper.GetString("api-listen-address")}
+			if socket := viper.GetString("api-socket"); socket != "" {
+				addresses = append(addresses, "unix:"+socket)
+			}
+			for _, address := range addresses {
+				if address == "" {
+					continue
+				}
+				wg.Add(1)
+				go func(address string) {
+					defer wg.Done()
+					if err := chkr.ServeAPI(address, stopCh); err != nil {
+						log.Error(fmt.Errorf("error during checker API serve on %s. %w", address, err))
+					}
+				}(address)
+			}
+
+			if viper.GetBool("config-reload") {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					userconfig.Watch(cfgFile, globalCfgFile, viper.GetDuration("config-rescan-interval"), stopCh, func(cfgs *userconfig.Config) {
+						userconfig.Cfg.SetServers(cfgs.Servers)
+						chkr.Reconcile(cfgs.Servers)
+					})
+				}()
+			}
+		}
+
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := checker.New().Run(stopCh); err != nil {
-				log.Error(fmt.Errorf("error during checker.Run(). %w", err))
+			if lock != nil {
+				locked, err := lock.Wait(stopCh, viper.GetDuration("standby-interval"))
+				if err != nil {
+					runErr = fmt.Errorf("error while waiting for checker lock. %w", err)
+					log.Error(runErr)
+					sigCh <- syscall.SIGTERM
+					return
+				}
+				if !locked {
+					return
+				}
+				log.Infof("acquired checker lock %s", viper.GetString("lock-file"))
+			}
+			startServices()
+			if err := chkr.Run(stopCh); err != nil {
+				runErr = fmt.Errorf("error during checker.Run(). %w", err)
+				log.Error(runErr)
+				sigCh <- syscall.SIGTERM
 			}
 		}()
 
@@ -75,7 +156,7 @@ var checkerCmd = &cobra.Command{
 
 		log.Info("exiting checker")
 
-		return nil
+		return runErr
 	},
 }
 
@@ -84,11 +165,59 @@ func init() {
 	checkerCmd.PersistentFlags().String("log-level", "INFO", "log level")
 	checkerCmd.PersistentFlags().Bool("debug", false, "debug mode")
 	checkerCmd.PersistentFlags().Bool("dockerevents-checker", false, "if the dockerevents-checker should be enabled")
+	checkerCmd.PersistentFlags().String("api-listen-address", checker.DefaultAPIAddress, "checker status API and metrics listen address, either HOST:PORT or unix:PATH (empty to disable)")
+	checkerCmd.PersistentFlags().String("api-socket", checker.DefaultAPISocket, "checker API unix socket, changes to the checker state (e.g., clearing a quarantine) are only allowed over it by the checker user and group (empty to disable)")
+	checkerCmd.PersistentFlags().Bool("config-reload", true, "if the configs should be watched and reloaded on changes")
+	checkerCmd.PersistentFlags().Duration("config-rescan-interval", 1*time.Minute, "interval in which the server directories are rescanned for config changes")
+	checkerCmd.PersistentFlags().String("state-file", defaultStateFile(), "checker state file to persist the check result counters, restart budgets and maintenance silences across restarts (empty to disable)")
+	checkerCmd.PersistentFlags().Duration("state-save-interval", 30*time.Second, "interval in which the checker state file is saved")
+	checkerCmd.PersistentFlags().String("journal-file", defaultJournalFile(), "journal file every checker decision (executed or dry-run) is appended to (empty to disable)")
+	checkerCmd.PersistentFlags().Int("restart-concurrency", 2, "how many server restarts the checker runs at the same time (0 for no limit)")
+	checkerCmd.PersistentFlags().Duration("restart-spacing", 15*time.Second, "minimum time between the start of two server restarts by the checker")
+	checkerCmd.PersistentFlags().String("lock-file", defaultLockFile(), "lock file to ensure only one checker is active at a time (empty to disable)")
+	checkerCmd.PersistentFlags().Bool("standby", false, "wait in standby and take over when the lock is held by another checker instead of exiting")
+	checkerCmd.PersistentFlags().Duration("standby-interval", 5*time.Second, "interval in which a checker in standby tries to acquire the lock")
 
 	viper.BindPFlag("dry-run", checkerCmd.PersistentFlags().Lookup("dry-run"))
 	viper.BindPFlag("log-level", checkerCmd.PersistentFlags().Lookup("log-level"))
 	viper.BindPFlag("debug", checkerCmd.PersistentFlags().Lookup("debug"))
 	viper.BindPFlag("dockerevents-checker", checkerCmd.PersistentFlags().Lookup("dockerevents-checker"))
+	viper.BindPFlag("api-listen-address", checkerCmd.PersistentFlags().Lookup("api-listen-address"))
+	viper.BindPFlag("api-socket", checkerCmd.PersistentFlags().Lookup("api-socket"))
+	viper.BindPFlag("config-reload", checkerCmd.PersistentFlags().Lookup("config-reload"))
+	viper.BindPFlag("config-rescan-interval", checkerCmd.PersistentFlags().Lookup("config-rescan-interval"))
+	viper.BindPFlag("state-file", checkerCmd.PersistentFlags().Lookup("state-file"))
+	viper.BindPFlag("state-save-interval", checkerCmd.PersistentFlags().Lookup("state-save-interval"))
+	viper.BindPFlag("journal-file", checkerCmd.PersistentFlags().Lookup("journal-file"))
+	viper.BindPFlag("restart-concurrency", checkerCmd.PersistentFlags().Lookup("restart-concurrency"))
+	viper.BindPFlag("restart-spacing", checkerCmd.PersistentFlags().Lookup("restart-spacing"))
+	viper.BindPFlag("lock-file", checkerCmd.PersistentFlags().Lookup("lock-file"))
+	viper.BindPFlag("standby", checkerCmd.PersistentFlags().Lookup("standby"))
+	viper.BindPFlag("standby-interval", checkerCmd.PersistentFlags().Lookup("standby-interval"))
 
 	rootCmd.AddCommand(checkerCmd)
 }
+
+func defaultStateFile() string {
+	home, err := homedir.Dir()
+	if err != nil {
+		return ""
+	}
+	return path.Join(home, ".srcds_controller_checker_state.json")
+}
+
+func defaultLockFile() string {
+	home, err := homedir.Dir()
+	if err != nil {
+		return ""
+	}
+	return path.Join(home, ".srcds_controller_checker.lock")
+}
+
+func defaultJournalFile() string {
+	home, err := homedir.Dir()
+	if err != nil {
+		return ""
+	}
+	return path.Join(home, ".srcds_controller_checker_journal.jsonl")
+}
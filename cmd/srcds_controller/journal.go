@@ -0,0 +1,68 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+
+	"github.com/galexrt/srcds_controller/pkg/checker"
+	"github.com/spf13/cobra"
+)
+
+// journalCmd show the checker decision journal
+var journalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "Show the checker decision journal",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		journalFile, err := cmd.Flags().GetString("journal-file")
+		if err != nil {
+			return err
+		}
+		serverNames, err := cmd.Flags().GetStringSlice("server")
+		if err != nil {
+			return err
+		}
+		tail, err := cmd.Flags().GetInt("tail")
+		if err != nil {
+			return err
+		}
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return err
+		}
+
+		servers := map[string]bool{}
+		for _, name := range serverNames {
+			servers[name] = true
+		}
+
+		entries, err := checker.NewJournal(journalFile).Read(servers, tail)
+		if err != nil {
+			return err
+		}
+		return checker.WriteJournal(os.Stdout, entries, asJSON)
+	},
+}
+
+func init() {
+	journalCmd.Flags().String("journal-file", defaultJournalFile(), "checker journal file")
+	journalCmd.Flags().StringSlice("server", []string{}, "only show entries of these servers")
+	journalCmd.Flags().Int("tail", 50, "number of entries to show (0 for all)")
+	journalCmd.Flags().Bool("json", false, "output the entries as JSON lines")
+
+	rootCmd.AddCommand(journalCmd)
+}
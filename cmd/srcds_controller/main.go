@@ -17,7 +17,6 @@ limitations under the License.
 package main
 
 import (
-	"io/ioutil"
 	"os"
 	"path"
 	"syscall"
@@ -30,7 +29,6 @@ import (
 	homedir "github.com/mitchellh/go-homedir"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	yaml "gopkg.in/yaml.v2"
 )
 
 var (
@@ -90,36 +88,9 @@ func initConfig() {
 		cfgFile = path.Join(home, ".srcds_controller.yaml")
 	}
 
-	// Load global config
-	globalCfg := &config.GlobalConfig{}
-	if _, err := os.Stat(globalCfgFile); err == nil {
-		out, err := ioutil.ReadFile(globalCfgFile)
-		if err != nil {
-			log.Fatal(err)
-		}
-		if err = yaml.Unmarshal(out, globalCfg); err != nil {
-			log.Fatal(err)
-		}
-	}
-
-	userCfg := &userconfig.UserConfig{}
-	cfgs := &userconfig.Config{
-		Servers: map[string]*config.Config{},
-	}
-
-	if _, err := os.Stat(cfgFile); err == nil {
-		out, err := ioutil.ReadFile(cfgFile)
-		if err != nil {
-			log.Fatal(err)
-		}
-		if err = yaml.Unmarshal(out, userCfg); err != nil {
-			log.Fatal(err)
-		}
-		if err = userCfg.Load(globalCfg, cfgs); err != nil {
-			log.Fatal(err)
-		}
-	} else {
-		log.Fatal("no config found in home dir nor specified by flag")
+	_, cfgs, err := userconfig.LoadFiles(cfgFile, globalCfgFile)
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	userconfig.Cfg = cfgs
@@ -0,0 +1,198 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// HealthCheckMarker command written to the server console by the health
+	// probe, the "Unknown command" response shows the console is responsive
+	HealthCheckMarker = "srcds_controller_healthcheck"
+	// healthCheckResponse response of the server console to the marker, the
+	// marker itself is echoed by the tty even when the server is hung
+	healthCheckResponse = `Unknown command "` + HealthCheckMarker + `"`
+	// defaultHealthProbeTimeout how long the health probe waits for the response
+	defaultHealthProbeTimeout = 5 * time.Second
+)
+
+// healthProbe waits for the server console to respond to the health check
+// marker command
+type healthProbe struct {
+	sync.Mutex
+	startedAt time.Time
+	exited    bool
+	waiters   []chan struct{}
+	// tail end of the previous output, the response can be split across
+	// output reads
+	tail string
+}
+
+var health = &healthProbe{
+	startedAt: time.Now(),
+}
+
+// observe notify the waiting probes when the output contains the response
+// to the marker command
+func (h *healthProbe) observe(output string) {
+	h.Lock()
+	defer h.Unlock()
+
+	text := h.tail + output
+	h.tail = text
+	if keep := len(healthCheckResponse) - 1; len(text) > keep {
+		h.tail = text[len(text)-keep:]
+	}
+	if !strings.Contains(text, healthCheckResponse) {
+		return
+	}
+	h.tail = ""
+	for _, waiter := range h.waiters {
+		close(waiter)
+	}
+	h.waiters = nil
+}
+
+func (h *healthProbe) setExited() {
+	h.Lock()
+	defer h.Unlock()
+	h.exited = true
+}
+
+// probe write the marker command to the console and wait for the response
+func (h *healthProbe) probe(timeout time.Duration) error {
+	h.Lock()
+	if h.exited {
+		h.Unlock()
+		return fmt.Errorf("gameserver process has exited")
+	}
+	waiter := make(chan struct{})
+	h.waiters = append(h.waiters, waiter)
+	h.Unlock()
+	// Don't keep the waiter around when no response arrives, otherwise a hung
+	// server collects one per probe
+	defer h.removeWaiter(waiter)
+
+	if tty == nil {
+		return fmt.Errorf("cmd tty is nil")
+	}
+	consoleMutex.Lock()
+	_, err := tty.Write([]byte(HealthCheckMarker + "\n"))
+	consoleMutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to write health check command to server console. %w", err)
+	}
+
+	select {
+	case <-waiter:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("server console didn't respond within %s", timeout)
+	}
+}
+
+// removeWaiter remove the waiter from the list, it has already been removed
+// when the response arrived
+func (h *healthProbe) removeWaiter(waiter chan struct{}) {
+	h.Lock()
+	defer h.Unlock()
+	for i, w := range h.waiters {
+		if w == waiter {
+			h.waiters = append(h.waiters[:i], h.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+func healthCheck(c *gin.Context) {
+	timeout := defaultHealthProbeTimeout
+	if raw := c.Query("timeout"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			c.String(http.StatusBadRequest, "invalid timeout")
+			return
+		}
+		timeout = parsed
+	}
+
+	// The server is still starting (e.g., loading the map), the vendored
+	// Docker API has no healthcheck start period so the runner handles it
+	if raw := c.Query("startPeriod"); raw != "" {
+		startPeriod, err := time.ParseDuration(raw)
+		if err != nil {
+			c.String(http.StatusBadRequest, "invalid startPeriod")
+			return
+		}
+		health.Lock()
+		starting := !health.exited && time.Since(health.startedAt) < startPeriod
+		health.Unlock()
+		if starting {
+			c.String(http.StatusOK, "starting")
+			return
+		}
+	}
+
+	if err := health.probe(timeout); err != nil {
+		c.String(http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	c.String(http.StatusOK, "healthy")
+}
+
+// runHealthCheck run the `healthcheck` subcommand, used as the Docker
+// HEALTHCHECK of the server container. Returns the exit code.
+func runHealthCheck(args []string) int {
+	flags := flag.NewFlagSet("healthcheck", flag.ExitOnError)
+	timeout := flags.Duration("timeout", defaultHealthProbeTimeout, "how long to wait for the server console to respond")
+	startPeriod := flags.Duration("start-period", 0, "time after the runner start during which the server is considered healthy")
+	flags.Parse(args)
+
+	httpc := http.Client{
+		Timeout: *timeout + 2*time.Second,
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
+				return net.Dial("unix", ListenAddress)
+			},
+		},
+	}
+
+	resp, err := httpc.Get(fmt.Sprintf("http://unixlocalhost/health?timeout=%s&startPeriod=%s", *timeout, *startPeriod))
+	if err != nil {
+		fmt.Printf("unhealthy: %+v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	out, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		fmt.Printf("unhealthy: %s\n", out)
+		return 1
+	}
+	fmt.Println(string(out))
+	return 0
+}
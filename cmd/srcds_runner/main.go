@@ -122,6 +122,10 @@ func setupServerArgs() []string {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
+		os.Exit(runHealthCheck(os.Args[2:]))
+	}
+
 	// Enable gops agent for troubleshooting
 	if err := agent.Listen(agent.Options{
 		ShutdownCleanup: true,
@@ -173,6 +177,7 @@ func main() {
 	r.Use(gin.Recovery())
 	r.GET("/", cmdExecute)
 	r.POST("/", cmdExecute)
+	r.GET("/health", healthCheck)
 	go listenAndServe(r)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -189,6 +194,7 @@ func main() {
 	go func() {
 		defer wg.Done()
 		err := cmd.Wait()
+		health.setExited()
 		logger.Warnf("process has exited, sending interrupt to runner. %+v", err)
 		sigs <- os.Interrupt
 	}()
@@ -282,6 +288,7 @@ func copyLogs(r io.Reader) error {
 			)
 
 			outLine = cleanOutput(outLine)
+			health.observe(outLine)
 
 			if lineToStderr(outLine) {
 				os.Stderr.Write([]byte(
@@ -392,7 +399,7 @@ func cleanOutput(in string) string {
 }
 
 func lineToStderr(in string) bool {
-	if strings.Contains(in, "srcds_controller_check") {
+	if strings.Contains(in, "srcds_controller_check") || strings.Contains(in, HealthCheckMarker) {
 		return true
 	}
 
@@ -0,0 +1,49 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actions
+
+import (
+	"context"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/checks"
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+// Action an action which is run when a check limit has been reached
+type Action interface {
+	// Run run the action for the server of the event. The returned error is
+	// recorded by the checker.
+	Run(ctx context.Context, event Event) error
+}
+
+// Controller allows actions to change how the checker handles a server
+type Controller interface {
+	// DisableServer stop running checks and actions for the server until
+	// the checker is restarted
+	DisableServer(name string, reason string)
+}
+
+// Event the check limit event an action is run for
+type Event struct {
+	Server  *config.Config
+	CheckID string
+	Result  checks.Result
+	Time    time.Time
+	// Controller of the checker, can be nil when not run by the checker
+	Controller Controller
+}
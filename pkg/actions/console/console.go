@@ -0,0 +1,50 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package console
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/galexrt/srcds_controller/pkg/actions"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/server"
+)
+
+func init() {
+	actions.Register("console", New)
+}
+
+// Action console action sending a command to the server console
+type Action struct {
+	command string
+}
+
+// New return a new console action, the `command` opt is required
+func New(opts config.CheckOpts) (actions.Action, error) {
+	if opts["command"] == "" {
+		return nil, fmt.Errorf("no command given for console action")
+	}
+	return &Action{
+		command: opts["command"],
+	}, nil
+}
+
+// Run send the command to the server of the event
+func (a *Action) Run(ctx context.Context, event actions.Event) error {
+	return server.SendCommand(event.Server, []string{a.command})
+}
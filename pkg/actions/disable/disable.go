@@ -0,0 +1,76 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disable
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/galexrt/srcds_controller/pkg/actions"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/server"
+)
+
+var (
+	defaultOpts = config.CheckOpts{
+		"stop": "false",
+	}
+)
+
+func init() {
+	actions.Register("disable", New)
+}
+
+// Action disable action stopping all checks and actions of the server in the
+// checker, optionally stopping the server as well
+type Action struct {
+	stop bool
+}
+
+// New return a new disable action
+func New(opts config.CheckOpts) (actions.Action, error) {
+	opts, err := actions.MergeDefaults(opts, defaultOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	stop, err := strconv.ParseBool(opts["stop"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse disable stop opt. %w", err)
+	}
+
+	return &Action{
+		stop: stop,
+	}, nil
+}
+
+// Run disable the server of the event in the checker
+func (a *Action) Run(ctx context.Context, event actions.Event) error {
+	if event.Controller == nil {
+		return fmt.Errorf("disable action can only be run by the checker")
+	}
+
+	if a.stop {
+		if err := server.Stop(event.Server); err != nil {
+			return err
+		}
+	}
+
+	event.Controller.DisableServer(event.Server.Server.Name, fmt.Sprintf("check %s reached its limit: %s", event.CheckID, event.Result.Message))
+	return nil
+}
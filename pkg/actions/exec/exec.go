@@ -0,0 +1,100 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/actions"
+	execcheck "github.com/galexrt/srcds_controller/pkg/checks/exec"
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+var (
+	defaultOpts = config.CheckOpts{
+		"timeout": "1m",
+		"shell":   "/bin/sh",
+	}
+)
+
+func init() {
+	actions.Register("exec", New)
+}
+
+// Action exec action running a script / command
+type Action struct {
+	command string
+	shell   string
+	timeout time.Duration
+}
+
+// New return a new exec action, the `command` opt is required
+func New(opts config.CheckOpts) (actions.Action, error) {
+	opts, err := actions.MergeDefaults(opts, defaultOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts["command"] == "" {
+		return nil, fmt.Errorf("no command given for exec action")
+	}
+
+	timeout, err := time.ParseDuration(opts["timeout"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse exec timeout opt. %w", err)
+	}
+
+	return &Action{
+		command: opts["command"],
+		shell:   opts["shell"],
+		timeout: timeout,
+	}, nil
+}
+
+// Run run the command in the server directory, the server and the check
+// result are passed as environment variables
+func (a *Action) Run(ctx context.Context, event actions.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	cmd := exec.Command(a.shell, "-c", a.command)
+	cmd.Dir = event.Server.Server.Path
+	cmd.Env = append(os.Environ(), execcheck.ServerEnv(event.Server)...)
+	cmd.Env = append(cmd.Env,
+		fmt.Sprintf("SRCDS_CHECK_ID=%s", event.CheckID),
+		fmt.Sprintf("SRCDS_CHECK_STATUS=%s", event.Result.Status),
+		fmt.Sprintf("SRCDS_CHECK_MESSAGE=%s", event.Result.Message),
+	)
+
+	out := &bytes.Buffer{}
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	if err := execcheck.RunCommand(ctx, cmd); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("exec action command timed out after %s", a.timeout)
+		}
+		return fmt.Errorf("exec action command failed. %w (output: %s)", err, strings.TrimSpace(out.String()))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/actions"
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+func TestRunTimeoutChildProcess(t *testing.T) {
+	event := actions.Event{
+		Server: &config.Config{
+			Server: &config.Server{
+				Name: "server1",
+			},
+		},
+		CheckID: "check1",
+	}
+
+	a, err := New(config.CheckOpts{"command": "sleep 5; echo hi", "timeout": "1s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := a.Run(context.Background(), event); err == nil {
+		t.Error("expected exec action to time out")
+	}
+	if took := time.Since(start); took > 3*time.Second {
+		t.Errorf("expected exec action to time out after 1s, took %s", took)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/imdario/mergo"
+)
+
+// Actions registry of the available actions by name
+var Actions = map[string]Factory{}
+
+// Factory creates an Action from the given action options. An error must be
+// returned when the options are invalid.
+type Factory func(opts config.CheckOpts) (Action, error)
+
+// Register add an action factory to the actions registry
+func Register(name string, factory Factory) {
+	Actions[name] = factory
+}
+
+// New create the action by name with the given limit action opts. Opts
+// prefixed with the action name and a dot (e.g., `console.command`) are only
+// passed to that action and take precedence over unprefixed opts.
+func New(name string, opts config.CheckOpts) (Action, error) {
+	name = strings.ToLower(name)
+	factory, ok := Actions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown action %s", name)
+	}
+
+	a, err := factory(OptsFor(name, opts))
+	if err != nil {
+		return nil, fmt.Errorf("invalid opts for action %s. %w", name, err)
+	}
+	return a, nil
+}
+
+// Validate validate the actions of the given check limit
+func Validate(limit *config.Limit) error {
+	if limit == nil {
+		return nil
+	}
+	for _, name := range limit.Actions {
+		if _, err := New(name, limit.ActionOpts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OptsFor return the opts for the given action name
+func OptsFor(name string, opts config.CheckOpts) config.CheckOpts {
+	prefix := name + "."
+	out := config.CheckOpts{}
+	for k, v := range opts {
+		if !strings.Contains(k, ".") {
+			out[k] = v
+		}
+	}
+	for k, v := range opts {
+		if strings.HasPrefix(k, prefix) {
+			out[strings.TrimPrefix(k, prefix)] = v
+		}
+	}
+	return out
+}
+
+// MergeDefaults merge the given default opts into the action opts
+func MergeDefaults(opts config.CheckOpts, defaults config.CheckOpts) (config.CheckOpts, error) {
+	if opts == nil {
+		opts = config.CheckOpts{}
+	}
+	if err := mergo.Map(&opts, defaults); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actions
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+func TestOptsFor(t *testing.T) {
+	opts := config.CheckOpts{
+		"timeout":         "10s",
+		"command":         "say hello",
+		"exec.command":    "/usr/local/bin/notify.sh",
+		"webhook.timeout": "5s",
+	}
+
+	tests := map[string]config.CheckOpts{
+		"exec": {
+			"timeout": "10s",
+			"command": "/usr/local/bin/notify.sh",
+		},
+		"webhook": {
+			"timeout": "5s",
+			"command": "say hello",
+		},
+		"console": {
+			"timeout": "10s",
+			"command": "say hello",
+		},
+	}
+	for name, expected := range tests {
+		if got := OptsFor(name, opts); !reflect.DeepEqual(got, expected) {
+			t.Errorf("action %s: expected opts %+v, got %+v", name, expected, got)
+		}
+	}
+}
+
+func TestNewUnknownAction(t *testing.T) {
+	if _, err := New("doesnotexist", nil); err == nil {
+		t.Error("expected error for unknown action")
+	}
+}
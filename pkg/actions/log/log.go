@@ -0,0 +1,46 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"context"
+
+	"github.com/galexrt/srcds_controller/pkg/actions"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	actions.Register("log", New)
+}
+
+// Action log action which only logs the check limit event
+type Action struct{}
+
+// New return a new log action
+func New(opts config.CheckOpts) (actions.Action, error) {
+	return &Action{}, nil
+}
+
+// Run log the check limit event
+func (a *Action) Run(ctx context.Context, event actions.Event) error {
+	log.WithFields(log.Fields{
+		"server": event.Server.Server.Name,
+		"check":  event.CheckID,
+	}).Warnf("check limit reached, last result %s: %s", event.Result.Status, event.Result.Message)
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restart
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/actions"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/server"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	defaultNiceOpts = config.CheckOpts{
+		"countdown":     "1m",
+		"announce":      "say Server restart in %d second(s)!",
+		"announceTimes": "60,30,15,10,5,4,3,2,1",
+	}
+)
+
+func init() {
+	actions.Register("restart", New)
+	actions.Register("nicerestart", NewNice)
+}
+
+// Action restart action, optionally announcing the restart with a countdown
+type Action struct {
+	countdown *server.Countdown
+}
+
+// New return a new restart action restarting the server right away
+func New(opts config.CheckOpts) (actions.Action, error) {
+	return &Action{}, nil
+}
+
+// NewNice return a new restart action which counts down before restarting
+// the server. The remaining seconds are announced at the given `announceTimes`
+// using the `announce` command template.
+func NewNice(opts config.CheckOpts) (actions.Action, error) {
+	opts, err := actions.MergeDefaults(opts, defaultNiceOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	countdown, err := time.ParseDuration(opts["countdown"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse nicerestart countdown opt. %w", err)
+	}
+
+	if err := server.CheckAnnounceTemplate(opts["announce"]); err != nil {
+		return nil, fmt.Errorf("invalid nicerestart announce opt. %w", err)
+	}
+
+	announceTimes := map[int]bool{}
+	for _, raw := range strings.Split(opts["announceTimes"], ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		secs, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse nicerestart announceTimes opt. %w", err)
+		}
+		announceTimes[secs] = true
+	}
+
+	return &Action{
+		countdown: &server.Countdown{
+			Duration:        countdown,
+			AnnounceSeconds: opts["announce"],
+			Times:           announceTimes,
+		},
+	}, nil
+}
+
+// Run restart the server of the event after the countdown (if any)
+func (a *Action) Run(ctx context.Context, event actions.Event) error {
+	logger := log.WithField("server", event.Server.Server.Name)
+
+	if a.countdown != nil {
+		if err := a.countdown.Run(ctx, []*config.Config{event.Server}); err != nil {
+			return fmt.Errorf("restart %w", err)
+		}
+	}
+
+	logger.Infof("restarting server %s", event.Server.Server.Name)
+	if err := server.SendCommand(event.Server, []string{"say", "SRCDS CHECKER RESTART MARKER"}); err != nil {
+		logger.Error(err)
+	}
+	if err := server.Restart(event.Server); err != nil {
+		return err
+	}
+	logger.Infof("server %s restarted", event.Server.Server.Name)
+	return nil
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stop
+
+import (
+	"context"
+
+	"github.com/galexrt/srcds_controller/pkg/actions"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/server"
+)
+
+func init() {
+	actions.Register("stop", New)
+}
+
+// Action stop action stopping the server container
+type Action struct{}
+
+// New return a new stop action
+func New(opts config.CheckOpts) (actions.Action, error) {
+	return &Action{}, nil
+}
+
+// Run stop the server of the event
+func (a *Action) Run(ctx context.Context, event actions.Event) error {
+	return server.Stop(event.Server)
+}
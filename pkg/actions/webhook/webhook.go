@@ -0,0 +1,115 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/actions"
+	"github.com/galexrt/srcds_controller/pkg/checks"
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+var (
+	defaultOpts = config.CheckOpts{
+		"method":  http.MethodPost,
+		"timeout": "10s",
+	}
+)
+
+func init() {
+	actions.Register("webhook", New)
+}
+
+// Action webhook action sending the check limit event as JSON to an URL
+type Action struct {
+	url     string
+	method  string
+	timeout time.Duration
+}
+
+// Payload JSON body sent by the webhook action
+type Payload struct {
+	Server string        `json:"server"`
+	Check  string        `json:"check"`
+	Result checks.Result `json:"result"`
+	Time   time.Time     `json:"time"`
+}
+
+// New return a new webhook action, the `url` opt is required
+func New(opts config.CheckOpts) (actions.Action, error) {
+	opts, err := actions.MergeDefaults(opts, defaultOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts["url"] == "" {
+		return nil, fmt.Errorf("no url given for webhook action")
+	}
+
+	timeout, err := time.ParseDuration(opts["timeout"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse webhook timeout opt. %w", err)
+	}
+
+	return &Action{
+		url:     opts["url"],
+		method:  strings.ToUpper(opts["method"]),
+		timeout: timeout,
+	}, nil
+}
+
+// Run send the event to the webhook URL, non 2xx responses are errors
+func (a *Action) Run(ctx context.Context, event actions.Event) error {
+	body, err := json.Marshal(Payload{
+		Server: event.Server.Server.Name,
+		Check:  event.CheckID,
+		Result: event.Result,
+		Time:   event.Time,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, a.method, a.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request. %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook. %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		out, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("webhook returned status %d (response body: %s)", resp.StatusCode, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultAPIAddress default address of the checker API
+	DefaultAPIAddress = "127.0.0.1:8182"
+	// DefaultAPISocket default unix socket of the checker API, changes to the
+	// checker state (e.g., clearing a quarantine) are only allowed over it
+	DefaultAPISocket = "/run/srcds_controller/checker.sock"
+	// unixAddressPrefix prefix of an address to use a unix socket for the API
+	unixAddressPrefix = "unix:"
+)
+
+// ServeAPI serve the checker status API on the given address until the stop
+// channel is closed. The address is either `HOST:PORT` or `unix:PATH`.
+func (c *Checker) ServeAPI(address string, stopCh <-chan struct{}) error {
+	gin.SetMode(gin.ReleaseMode)
+	gin.DefaultWriter = ioutil.Discard
+
+	r := gin.New()
+	r.Use(gin.Recovery())
+	c.registerAPIRoutes(r)
+
+	l, err := listen(address)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Handler:           r,
+		ReadTimeout:       5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       25 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		ConnContext:       saveConnInContext,
+	}
+
+	go func() {
+		<-stopCh
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Errorf("error during checker API shutdown. %+v", err)
+		}
+	}()
+
+	log.Infof("checker API listening on %s", address)
+	if err := srv.Serve(l); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (c *Checker) registerAPIRoutes(r *gin.Engine) {
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	r.GET("/api/v1/status", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, c.Status())
+	})
+	r.GET("/api/v1/status/:server", func(ctx *gin.Context) {
+		for _, status := range c.Status() {
+			if status.Name == ctx.Param("server") {
+				ctx.JSON(http.StatusOK, status)
+				return
+			}
+		}
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "server not found"})
+	})
+	r.GET("/api/v1/journal", func(ctx *gin.Context) {
+		servers := map[string]bool{}
+		for _, name := range ctx.QueryArray("server") {
+			servers[name] = true
+		}
+		limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "0"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		// The API is started before the checker sets the journal
+		resultCounter.RLock()
+		journal := resultCounter.journal
+		resultCounter.RUnlock()
+		entries, err := journal.Read(servers, limit)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, entries)
+	})
+	r.GET("/api/v1/silences", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, resultCounter.Silences())
+	})
+	r.POST("/api/v1/silences", requirePeerCred, func(ctx *gin.Context) {
+		silence := &Silence{}
+		if err := ctx.ShouldBindJSON(silence); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if silence.Server == "" || !silence.End.After(time.Now()) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "silence needs a server and an end in the future"})
+			return
+		}
+		if silence.Start.IsZero() {
+			silence.Start = time.Now()
+		}
+		resultCounter.AddSilence(silence)
+		ctx.JSON(http.StatusOK, silence)
+	})
+	r.DELETE("/api/v1/silences/:server", requirePeerCred, func(ctx *gin.Context) {
+		if !resultCounter.RemoveSilence(ctx.Param("server")) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "server not silenced"})
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{"server": ctx.Param("server")})
+	})
+	r.GET("/api/v1/queue", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, restartQueue.Queue())
+	})
+	r.GET("/api/v1/quarantine", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, resultCounter.Quarantines())
+	})
+	r.POST("/api/v1/incidents/:server", requirePeerCred, func(ctx *gin.Context) {
+		incident := struct {
+			ID int `json:"id"`
+		}{}
+		if err := ctx.ShouldBindJSON(&incident); err != nil || incident.ID == 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "incident needs an id"})
+			return
+		}
+		resultCounter.TrackIncident(ctx.Param("server"), incident.ID)
+		ctx.JSON(http.StatusOK, gin.H{"server": ctx.Param("server"), "id": incident.ID})
+	})
+	r.DELETE("/api/v1/quarantine/:server", requirePeerCred, func(ctx *gin.Context) {
+		if !resultCounter.ClearQuarantine(ctx.Param("server")) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "server not quarantined"})
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{"server": ctx.Param("server")})
+	})
+}
+
+func listen(address string) (net.Listener, error) {
+	if !strings.HasPrefix(address, unixAddressPrefix) {
+		return net.Listen("tcp", address)
+	}
+
+	path := strings.TrimPrefix(address, unixAddressPrefix)
+	// Make sure no stale socket is present
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0660); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
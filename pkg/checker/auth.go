@@ -0,0 +1,92 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sys/unix"
+)
+
+type contextKey struct {
+	name string
+}
+
+// connContextKey context key of the connection in the request context
+var connContextKey = &contextKey{"http-conn"}
+
+// saveConnInContext save the connection in the request context, used as
+// http.Server.ConnContext
+func saveConnInContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey, c)
+}
+
+// requirePeerCred only allow requests over the unix socket from root, the
+// checker user or members of the checker group. Used for the routes changing
+// the checker state (silences, quarantine).
+func requirePeerCred(ctx *gin.Context) {
+	conn, _ := ctx.Request.Context().Value(connContextKey).(net.Conn)
+	if err := checkPeerCred(conn); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("permission denied. %s", err)})
+		return
+	}
+	ctx.Next()
+}
+
+func checkPeerCred(conn net.Conn) error {
+	unixConn, isUnix := conn.(*net.UnixConn)
+	if !isUnix {
+		return fmt.Errorf("changes are only allowed over the checker API socket")
+	}
+	f, err := unixConn.File()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cred, err := unix.GetsockoptUcred(int(f.Fd()), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	if err != nil {
+		return err
+	}
+	if cred.Uid == 0 || int(cred.Uid) == os.Getuid() || int(cred.Gid) == os.Getgid() {
+		return nil
+	}
+
+	userID := strconv.FormatUint(uint64(cred.Uid), 10)
+	userInfo, err := user.LookupId(userID)
+	if err != nil {
+		return err
+	}
+	userGroups, err := userInfo.GroupIds()
+	if err != nil {
+		return err
+	}
+	checkerGroup := strconv.Itoa(os.Getgid())
+	for _, g := range userGroups {
+		if g == checkerGroup {
+			return nil
+		}
+	}
+	return fmt.Errorf("request user (%s) is neither the checker user nor in the checker group", userID)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckPeerCred(t *testing.T) {
+	dir, err := ioutil.TempDir("", "srcds_controller_auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l, err := listen("unix:" + filepath.Join(dir, "checker.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	client, err := net.Dial("unix", filepath.Join(dir, "checker.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Same user as the checker
+	if err := checkPeerCred(conn); err != nil {
+		t.Errorf("expected checker user to be allowed, got %v", err)
+	}
+
+	tcp, _ := net.Pipe()
+	defer tcp.Close()
+	if err := checkPeerCred(tcp); err == nil {
+		t.Error("expected non unix socket connection to be denied")
+	}
+	if err := checkPeerCred(nil); err == nil {
+		t.Error("expected missing connection to be denied")
+	}
+}
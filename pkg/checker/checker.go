@@ -17,16 +17,20 @@ limitations under the License.
 package checker
 
 import (
+	"context"
+	"crypto/sha256"
+	"fmt"
 	"math/rand"
 	"sync"
 	"time"
 
-	"github.com/galexrt/srcds_controller/pkg/checks"
 	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/notify"
 	"github.com/galexrt/srcds_controller/pkg/userconfig"
 	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v2"
 )
 
 var (
@@ -35,6 +39,24 @@ var (
 
 // Checker checker struct
 type Checker struct {
+	sync.Mutex
+	ctx      context.Context
+	wg       *sync.WaitGroup
+	resultCh chan Result
+	servers  map[string]*runningServer
+	// disabled servers disabled by an action with the reason
+	disabled map[string]string
+}
+
+// runningServer the running checks of a server
+type runningServer struct {
+	checks *serverChecks
+	// hash of the server config, used to detect config changes
+	hash string
+	// checkHashes hash of each check config by check ID
+	checkHashes map[string]string
+	cancel      context.CancelFunc
+	done        chan struct{}
 }
 
 func init() {
@@ -43,81 +65,282 @@ func init() {
 
 // New return a new Checker
 func New() *Checker {
-	return &Checker{}
+	return &Checker{
+		servers:  map[string]*runningServer{},
+		disabled: map[string]string{},
+	}
 }
 
 // Run run checker logic
 func (c *Checker) Run(stopCh <-chan struct{}) error {
-	wg := sync.WaitGroup{}
-
-	resultCh := make(chan Result)
+	wg := &sync.WaitGroup{}
 
 	viper.Set("remove", false)
 
-	if viper.GetBool("dockerevents-checker") {
+	// Create all checks first so invalid check opts are caught before
+	// anything is started
+	servers := userconfig.Cfg.GetServers()
+	for _, server := range servers {
+		if !server.Server.Enabled {
+			continue
+		}
+		if _, err := newServerChecks(server); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	// Set before any goroutine using the result counter is started
+	resultCounter.Lock()
+	resultCounter.ctx = ctx
+	resultCounter.controller = c
+	resultCounter.journal = NewJournal(viper.GetString("journal-file"))
+	resultCounter.Unlock()
+
+	stateFile := viper.GetString("state-file")
+	if stateFile != "" {
+		if err := resultCounter.Load(stateFile); err != nil {
+			log.Errorf("failed to load checker state file %s. %+v", stateFile, err)
+		}
+		resultCounter.Prune(servers)
+		resultCounter.Lock()
+		resultCounter.stateFile = stateFile
+		resultCounter.Unlock()
+
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			CheckForDockerEvents(stopCh)
+			saveStatePeriodically(stateFile, viper.GetDuration("state-save-interval"), stopCh)
 		}()
 	}
 
-	for _, server := range userconfig.Cfg.Servers {
-		if !server.Server.Enabled {
-			log.Infof("server %s disabled", server.Server.Name)
-			continue
-		}
+	if viper.GetBool("dockerevents-checker") {
 		wg.Add(1)
-		go func(server *config.Config) {
+		go func() {
 			defer wg.Done()
-			for _, check := range server.Server.Checks {
-				log.WithFields(logrus.Fields{
-					"server": server.Server.Name,
-					"check":  check.Name,
-				}).Info("starting check")
-				wg.Add(1)
-				go func(check config.Check, server *config.Config) {
-					defer wg.Done()
-					for {
-						log.Debugf("running check %s", check.Name)
-						checkResult := checks.Checks[check.Name](check, server)
-						resultCh <- Result{
-							Check:  check,
-							Server: server,
-							Return: checkResult,
-						}
-
-						splayTime := calculateTimeSplay(server.Checker.Splay.Start, server.Checker.Splay.End)
-						waitTime := server.Checker.Interval + splayTime
-						log.Debugf("waitTime: %s, splayTime: %s", waitTime, splayTime)
-
-						select {
-						case <-time.After(waitTime):
-						case <-stopCh:
-							return
-						}
-					}
-				}(check, server)
-			}
-		}(server)
+			CheckForDockerEvents(stopCh)
+		}()
 	}
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		restartQueue.Run(stopCh, viper.GetInt("restart-concurrency"), viper.GetDuration("restart-spacing"))
+	}()
+
+	resultCh := make(chan Result)
 	go func() {
 		for {
 			select {
 			case result := <-resultCh:
 				resultCounter.Add(result)
+			case <-stopCh:
+				return
 			}
 		}
 	}()
 
+	c.Lock()
+	c.ctx = ctx
+	c.wg = wg
+	c.resultCh = resultCh
+	c.Unlock()
+
+	c.Reconcile(servers)
+
 	<-stopCh
 	wg.Wait()
-	close(resultCh)
+	notify.Wait()
 	log.Info("waitgroup successfully synced")
 	return nil
 }
 
+// Reconcile start, stop and update the running checks to match the given
+// server configs. Result counters of unchanged checks are kept.
+func (c *Checker) Reconcile(servers map[string]*config.Config) {
+	c.Lock()
+	defer c.Unlock()
+
+	// Not running yet, the checks are started by Run
+	if c.ctx == nil || c.ctx.Err() != nil {
+		return
+	}
+
+	for name, running := range c.servers {
+		server, ok := servers[name]
+		if ok && server.Server.Enabled {
+			continue
+		}
+		log.WithField("server", name).Info("server removed or disabled, stopping checks")
+		c.stopServer(name, running)
+		if !ok {
+			resultCounter.RemoveServer(name)
+			deleteServerMetrics(name)
+		}
+	}
+
+	for name, server := range servers {
+		if !server.Server.Enabled {
+			log.Debugf("server %s disabled", name)
+			continue
+		}
+		if reason, ok := c.disabled[name]; ok {
+			log.WithField("server", name).Debugf("server disabled by action (%s)", reason)
+			continue
+		}
+
+		hash, checkHashes, err := hashServer(server)
+		if err != nil {
+			log.WithField("server", name).Errorf("failed to hash server config. %+v", err)
+			continue
+		}
+
+		running, ok := c.servers[name]
+		if ok && running.hash == hash {
+			continue
+		}
+
+		srvChecks, err := newServerChecks(server)
+		if err != nil {
+			log.WithField("server", name).Errorf("invalid checks config, keeping current checks. %+v", err)
+			continue
+		}
+
+		if ok {
+			log.WithField("server", name).Info("server config changed, restarting checks")
+			c.stopServer(name, running)
+			for checkID, checkHash := range running.checkHashes {
+				if checkHashes[checkID] != checkHash {
+					resultCounter.Reset(name, checkID)
+				}
+			}
+		}
+
+		c.startServer(srvChecks, hash, checkHashes)
+	}
+
+	resultCounter.Prune(servers)
+}
+
+// DisableServer stop the checks of the server until the checker is restarted,
+// implements actions.Controller
+func (c *Checker) DisableServer(name string, reason string) {
+	c.Lock()
+	defer c.Unlock()
+
+	log.WithField("server", name).Warnf("disabling checks of server, %s", reason)
+	c.disabled[name] = reason
+	if running, ok := c.servers[name]; ok {
+		c.stopServer(name, running)
+	}
+}
+
+func (c *Checker) startServer(srvChecks *serverChecks, hash string, checkHashes map[string]string) {
+	server := srvChecks.server
+	for _, check := range server.Server.Checks {
+		log.WithFields(logrus.Fields{
+			"server": server.Server.Name,
+			"check":  check.GetID(),
+		}).Info("starting check")
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	running := &runningServer{
+		checks:      srvChecks,
+		hash:        hash,
+		checkHashes: checkHashes,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+	c.servers[server.Server.Name] = running
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer close(running.done)
+		c.runServer(ctx, srvChecks)
+	}()
+}
+
+func (c *Checker) stopServer(name string, running *runningServer) {
+	running.cancel()
+	<-running.done
+	delete(c.servers, name)
+}
+
+func (c *Checker) runServer(ctx context.Context, srvChecks *serverChecks) {
+	server := srvChecks.server
+	for {
+		results := srvChecks.tick(ctx, srvChecks.startedAt())
+		for _, result := range results {
+			select {
+			case c.resultCh <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+		resultCounter.observeIncidents(server, results)
+
+		nextRun := srvChecks.getNextRun()
+		waitTime := time.Until(nextRun)
+		if nextRun.IsZero() {
+			waitTime = server.Checker.Interval
+		}
+		log.WithField("server", server.Server.Name).Debugf("waitTime: %s", waitTime)
+
+		select {
+		case <-time.After(waitTime):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// hashServer hash the server config and each of its checks
+func hashServer(server *config.Config) (string, map[string]string, error) {
+	out, err := yaml.Marshal(server)
+	if err != nil {
+		return "", nil, err
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256(out))
+
+	checkHashes := map[string]string{}
+	for _, check := range server.Server.Checks {
+		out, err := yaml.Marshal(check)
+		if err != nil {
+			return "", nil, err
+		}
+		checkHashes[check.GetID()] = fmt.Sprintf("%x", sha256.Sum256(out))
+	}
+
+	return hash, checkHashes, nil
+}
+
+func saveStatePeriodically(stateFile string, interval time.Duration, stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-time.After(interval):
+		case <-stopCh:
+			if err := resultCounter.Save(stateFile); err != nil {
+				log.Errorf("failed to save checker state file %s. %+v", stateFile, err)
+			}
+			return
+		}
+		if err := resultCounter.Save(stateFile); err != nil {
+			log.Errorf("failed to save checker state file %s. %+v", stateFile, err)
+		}
+	}
+}
+
 func calculateTimeSplay(begin int, end int) time.Duration {
+	if end <= begin {
+		return time.Duration(begin) * time.Second
+	}
 	return time.Duration(rand.Intn(end-begin)+begin) * time.Second
 }
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+func testServerCfg(name string, checks ...config.Check) *config.Config {
+	return &config.Config{
+		Server: &config.Server{
+			Name:    name,
+			Enabled: true,
+			Checks:  checks,
+		},
+		Checker: &config.Checker{
+			Interval: time.Hour,
+			Splay: &config.Splay{
+				Start: 0,
+				End:   1,
+			},
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := New()
+	c.ctx = ctx
+	c.wg = &sync.WaitGroup{}
+	c.resultCh = make(chan Result)
+	go func() {
+		for {
+			select {
+			case <-c.resultCh:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	c.Reconcile(map[string]*config.Config{
+		"server1": testServerCfg("server1", staticCheckCfg("a", "OK"), staticCheckCfg("b", "OK")),
+		"server2": testServerCfg("server2", staticCheckCfg("a", "OK")),
+	})
+	if len(c.servers) != 2 {
+		t.Fatalf("expected 2 running servers, got %d", len(c.servers))
+	}
+	server1 := c.servers["server1"]
+
+	resultCounter.Lock()
+	resultCounter.results["server1"] = map[string]*ResultCounter{
+		"a": {Count: 1},
+		"b": {Count: 1},
+	}
+	resultCounter.Unlock()
+
+	// Unchanged server is kept running, check b changed and server2 removed
+	c.Reconcile(map[string]*config.Config{
+		"server1": testServerCfg("server1", staticCheckCfg("a", "OK"), staticCheckCfg("b", "WARNING")),
+	})
+	if len(c.servers) != 1 {
+		t.Fatalf("expected 1 running server, got %d", len(c.servers))
+	}
+	if c.servers["server1"] == server1 {
+		t.Error("expected checks of changed server1 to be restarted")
+	}
+	if _, ok := resultCounter.results["server1"]["a"]; !ok {
+		t.Error("expected result counter of unchanged check a to be kept")
+	}
+	if _, ok := resultCounter.results["server1"]["b"]; ok {
+		t.Error("expected result counter of changed check b to be reset")
+	}
+
+	server1 = c.servers["server1"]
+	c.Reconcile(map[string]*config.Config{
+		"server1": testServerCfg("server1", staticCheckCfg("a", "OK"), staticCheckCfg("b", "WARNING")),
+	})
+	if c.servers["server1"] != server1 {
+		t.Error("expected checks of unchanged server1 to be kept running")
+	}
+
+	cancel()
+	c.wg.Wait()
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client client for the checker API
+type Client struct {
+	httpc   *http.Client
+	baseURL string
+}
+
+// NewClient return a new checker API client for the given address, either
+// `HOST:PORT` or `unix:PATH`
+func NewClient(address string) *Client {
+	transport := &http.Transport{
+		IdleConnTimeout: 15 * time.Second,
+	}
+	baseURL := "http://" + address
+	if strings.HasPrefix(address, unixAddressPrefix) {
+		path := strings.TrimPrefix(address, unixAddressPrefix)
+		transport.DialContext = func(_ context.Context, _, _ string) (net.Conn, error) {
+			return net.Dial("unix", path)
+		}
+		baseURL = "http://unixlocalhost"
+	}
+
+	return &Client{
+		httpc: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: transport,
+		},
+		baseURL: baseURL,
+	}
+}
+
+// Status get the checker status of all servers
+func (c *Client) Status() ([]*ServerStatus, error) {
+	statuses := []*ServerStatus{}
+	if err := c.do(http.MethodGet, "/api/v1/status", nil, &statuses); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// Queue get the running and queued restarts
+func (c *Client) Queue() ([]*QueuedAction, error) {
+	queue := []*QueuedAction{}
+	if err := c.do(http.MethodGet, "/api/v1/queue", nil, &queue); err != nil {
+		return nil, err
+	}
+	return queue, nil
+}
+
+// Quarantines get the quarantined servers
+func (c *Client) Quarantines() ([]*Quarantine, error) {
+	quarantines := []*Quarantine{}
+	if err := c.do(http.MethodGet, "/api/v1/quarantine", nil, &quarantines); err != nil {
+		return nil, err
+	}
+	return quarantines, nil
+}
+
+// ClearQuarantine clear the quarantine of a server
+func (c *Client) ClearQuarantine(serverName string) error {
+	return c.do(http.MethodDelete, "/api/v1/quarantine/"+url.PathEscape(serverName), nil, nil)
+}
+
+// TrackIncident hand over a status page incident opened for a server to the
+// checker, which resolves it once the server is healthy again
+func (c *Client) TrackIncident(serverName string, id int) error {
+	return c.do(http.MethodPost, "/api/v1/incidents/"+url.PathEscape(serverName), map[string]int{"id": id}, nil)
+}
+
+// Journal get the last journal entries of the given servers (all servers if
+// none are given), a limit of 0 returns all entries
+func (c *Client) Journal(servers []string, limit int) ([]*JournalEntry, error) {
+	query := url.Values{}
+	for _, name := range servers {
+		query.Add("server", name)
+	}
+	query.Set("limit", strconv.Itoa(limit))
+
+	entries := []*JournalEntry{}
+	if err := c.do(http.MethodGet, "/api/v1/journal?"+query.Encode(), nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Silences get the active maintenance silences
+func (c *Client) Silences() ([]*Silence, error) {
+	silences := []*Silence{}
+	if err := c.do(http.MethodGet, "/api/v1/silences", nil, &silences); err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
+
+// AddSilence add (or replace) the maintenance silence of a server
+func (c *Client) AddSilence(silence *Silence) error {
+	return c.do(http.MethodPost, "/api/v1/silences", silence, nil)
+}
+
+// RemoveSilence remove the maintenance silence of a server
+func (c *Client) RemoveSilence(serverName string) error {
+	return c.do(http.MethodDelete, "/api/v1/silences/"+url.PathEscape(serverName), nil, nil)
+}
+
+func (c *Client) do(method string, path string, in interface{}, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		reqBody, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(reqBody)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpc.Do(req)
+	if err != nil {
+		return fmt.Errorf("error during request to checker API. %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read checker API response body. %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("checker API returned status %d (response body: %s)", resp.StatusCode, strings.ReplaceAll(string(respBody), "\n", "\\n"))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
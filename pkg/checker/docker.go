@@ -20,84 +20,340 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/notify"
 	"github.com/galexrt/srcds_controller/pkg/server"
+	"github.com/galexrt/srcds_controller/pkg/statuspage"
 	"github.com/galexrt/srcds_controller/pkg/userconfig"
+	"github.com/galexrt/srcds_controller/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
-// CheckForDockerEvents check for docker container events and react to certain events
+const (
+	// dockerEventsMinBackoff and dockerEventsMaxBackoff reconnect backoff of
+	// the Docker events stream
+	dockerEventsMinBackoff = 1 * time.Second
+	dockerEventsMaxBackoff = 1 * time.Minute
+	// intentionalStopWindow a die event within this time after a kill event
+	// is caused by a stop (e.g., `sc stop`) and not a crash
+	intentionalStopWindow = 2 * time.Minute
+)
+
+// dockerEventWatcher watches the Docker events of the server containers and
+// reacts to them as configured per server
+type dockerEventWatcher struct {
+	sync.Mutex
+	// since time of the last seen event, used to resume after reconnects
+	since time.Time
+	// kills time of the last kill event per server
+	kills map[string]time.Time
+	// dies times of the die events (crashes) per server in the crash loop window
+	dies map[string][]time.Time
+}
+
+func newDockerEventWatcher() *dockerEventWatcher {
+	return &dockerEventWatcher{
+		since: time.Now(),
+		kills: map[string]time.Time{},
+		dies:  map[string][]time.Time{},
+	}
+}
+
+// CheckForDockerEvents watch the Docker container events and react to them
+// until the stop channel is closed. The events stream is reconnected with
+// backoff on errors and resumed from the last seen event.
 func CheckForDockerEvents(stopCh <-chan struct{}) {
+	w := newDockerEventWatcher()
+	backoff := dockerEventsMinBackoff
+	for {
+		connectedAt := time.Now()
+		err := w.watch(stopCh)
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
+
+		// Reset the backoff when the stream has been working for a while
+		if time.Since(connectedAt) > dockerEventsMaxBackoff {
+			backoff = dockerEventsMinBackoff
+		}
+		log.WithError(err).Errorf("docker events stream failed, reconnecting in %s", backoff)
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > dockerEventsMaxBackoff {
+			backoff = dockerEventsMaxBackoff
+		}
+		dockerEventsReconnectsTotal.Inc()
+	}
+}
+
+func (w *dockerEventWatcher) watch(stopCh <-chan struct{}) error {
 	filterArgs := filters.NewArgs()
-	filterArgs.Add("app", "gameserver")
-	filterArgs.Add("managed-by", "srcds_controller")
+	filterArgs.Add("type", "container")
+	filterArgs.Add("label", "app=gameserver")
+	filterArgs.Add("label", "managed-by=srcds_controller")
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+
+	w.Lock()
+	since := w.since
+	w.Unlock()
 	eventStream, errChan := server.DockerCli.Events(ctx, types.EventsOptions{
 		Filters: filterArgs,
-		Since:   "0s",
+		Since:   fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond()),
 	})
+	log.Infof("watching docker events since %s", since.Format(time.RFC3339))
 
 	for {
 		select {
 		case <-stopCh:
-			return
+			return nil
 		case event := <-eventStream:
-			log.Debug("received docker event")
-			if _, ok := event.Actor.Attributes["name"]; ok {
-				// Iterate over usercfg.Cfg.Servers and check which server matches by name
-				for _, srv := range userconfig.Cfg.Servers {
-					if event.Actor.Attributes["name"] == srv.Docker.NamePrefix+srv.Server.Name {
-						event.Actor.Attributes["name"] = strings.TrimPrefix(event.Actor.Attributes["name"], srv.Docker.NamePrefix)
-						if err := handleDockerEvent(event); err != nil {
-							log.Error(err)
-						}
-					}
-				}
-			} else {
-				log.Error(fmt.Errorf("no container name in docker event attributes"))
+			eventTime := time.Unix(0, event.TimeNano)
+			w.Lock()
+			// Events at the resume timestamp can be received again
+			if !eventTime.After(w.since) {
+				w.Unlock()
+				continue
+			}
+			w.since = eventTime
+			w.Unlock()
+
+			serverCfg, ok := findServerByContainer(event.Actor.Attributes["name"])
+			if !ok {
+				log.Debugf("docker event: no server config found for container %s", event.Actor.Attributes["name"])
+				continue
+			}
+			if err := w.handle(serverCfg, event); err != nil {
+				log.WithField("server", serverCfg.Server.Name).Error(err)
 			}
 		case err := <-errChan:
-			if err != nil {
-				log.WithError(err).Error("received error from docker events stream")
-				return
+			if err == nil {
+				err = fmt.Errorf("docker events stream closed")
 			}
+			return err
+		}
+	}
+}
+
+func findServerByContainer(containerName string) (*config.Config, bool) {
+	if containerName == "" {
+		return nil, false
+	}
+	for _, srv := range userconfig.Cfg.GetServers() {
+		if srv.Docker != nil && util.GetContainerName(srv.Docker.NamePrefix, srv.Server.Name) == containerName {
+			return srv, true
 		}
 	}
+	return nil, false
 }
 
-func handleDockerEvent(event events.Message) error {
-	eventAction := strings.ToLower(event.Action)
-	switch eventAction {
-	case "die":
-		if _, ok := event.Actor.Attributes["name"]; !ok {
-			return fmt.Errorf("docker event: event has no container name in it")
+// handle map the Docker event to an event type and run the configured reaction
+func (w *dockerEventWatcher) handle(serverCfg *config.Config, event events.Message) error {
+	serverName := serverCfg.Server.Name
+	if !serverCfg.Server.Enabled {
+		return nil
+	}
+
+	action := strings.ToLower(event.Action)
+	eventTime := time.Unix(0, event.TimeNano)
+	message := ""
+	eventType := ""
+	switch {
+	case action == "kill":
+		w.Lock()
+		w.kills[serverName] = eventTime
+		w.Unlock()
+		eventType = config.DockerEventKill
+		message = fmt.Sprintf("server container killed (signal: %s)", event.Actor.Attributes["signal"])
+	case action == "die":
+		w.Lock()
+		killedAt, killed := w.kills[serverName]
+		delete(w.kills, serverName)
+		w.Unlock()
+		if killed && eventTime.Sub(killedAt) < intentionalStopWindow {
+			log.WithField("server", serverName).Info("docker event: server container stopped intentionally, not reacting")
+			resultCounter.journal.Append(JournalEntry{
+				Server:   serverName,
+				Source:   SourceDockerEvent,
+				Event:    config.DockerEventDie,
+				Decision: DecisionIntentionalStop,
+				DryRun:   viper.GetBool("dry-run"),
+			})
+			return nil
 		}
-		serverName := event.Actor.Attributes["name"]
-		serverCfg, ok := userconfig.Cfg.Servers[serverName]
-		if !ok {
-			return fmt.Errorf("docker event: unable to find server config for %s", serverName)
+
+		eventType = config.DockerEventDie
+		message = fmt.Sprintf("server container died (exit code: %s)", event.Actor.Attributes["exitCode"])
+		if w.crashLoop(serverCfg, eventTime) {
+			eventType = config.DockerEventCrashLoop
+			message = fmt.Sprintf("server container is crash looping, died %d times in %s (last exit code: %s)", serverCfg.Checker.DockerEvents.CrashLoopCount, serverCfg.Checker.DockerEvents.CrashLoopWindow, event.Actor.Attributes["exitCode"])
+		}
+	case action == "oom":
+		eventType = config.DockerEventOOM
+		message = "server container ran out of memory"
+	case strings.HasPrefix(action, "health_status") && strings.HasSuffix(action, "unhealthy"):
+		eventType = config.DockerEventUnhealthy
+		message = "server container health check is unhealthy"
+	default:
+		log.WithField("event_action", action).Debug("docker event: event isn't of our concern")
+		return nil
+	}
+
+	return w.react(serverCfg, eventType, message)
+}
+
+// crashLoop record the die event and return true if the server died too
+// often in the crash loop window, the die history is reset then
+func (w *dockerEventWatcher) crashLoop(serverCfg *config.Config, eventTime time.Time) bool {
+	if serverCfg.Checker == nil || serverCfg.Checker.DockerEvents == nil || serverCfg.Checker.DockerEvents.CrashLoopCount <= 0 {
+		return false
+	}
+	cfg := serverCfg.Checker.DockerEvents
+
+	w.Lock()
+	defer w.Unlock()
+
+	dies := []time.Time{}
+	for _, t := range w.dies[serverCfg.Server.Name] {
+		if eventTime.Sub(t) < cfg.CrashLoopWindow {
+			dies = append(dies, t)
 		}
+	}
+	dies = append(dies, eventTime)
+	if len(dies) >= cfg.CrashLoopCount {
+		delete(w.dies, serverCfg.Server.Name)
+		return true
+	}
+	w.dies[serverCfg.Server.Name] = dies
+	return false
+}
+
+// react run the reaction configured for the event type of the server
+func (w *dockerEventWatcher) react(serverCfg *config.Config, eventType string, message string) error {
+	serverName := serverCfg.Server.Name
+	logger := log.WithField("server", serverName)
 
-		if !serverCfg.Server.Enabled {
+	var reaction string
+	if serverCfg.Checker != nil {
+		reaction = serverCfg.Checker.DockerEvents.GetReaction(eventType)
+	}
+	if reaction == "" || reaction == config.ReactionIgnore {
+		logger.Debugf("docker event: ignoring %s event. %s", eventType, message)
+		return nil
+	}
+
+	entry := JournalEntry{
+		Server:   serverName,
+		Source:   SourceDockerEvent,
+		Event:    eventType,
+		Decision: DecisionAction,
+		Action:   reaction,
+		DryRun:   viper.GetBool("dry-run"),
+	}
+
+	if reaction != config.ReactionLog {
+		if reason, ok := resultCounter.silenced(serverCfg); ok {
+			logger.Infof("docker event: not reacting to %s event, %s", eventType, reason)
+			entry.Decision = DecisionSilenced
+			entry.Error = reason
+			resultCounter.journal.Append(entry)
 			return nil
 		}
+	}
 
-		if viper.GetBool("dry-run") {
-			log.WithField("server", serverName).Info("docker event: dry-run mode active, server restart")
-		} else {
-			log.WithField("server", serverName).Info("docker event: restarting server")
+	notifyEvent := notify.Event{
+		Type:    dockerNotifyEventType(eventType),
+		Message: message,
+	}
+
+	switch reaction {
+	case config.ReactionLog:
+		logger.Warnf("docker event: %s. %s", eventType, message)
+		entry.Executed = true
+	case config.ReactionNotify:
+		logger.Warnf("docker event: %s, sending notification. %s", eventType, message)
+		notify.SendAsync(serverCfg, notifyEvent)
+		entry.Executed = true
+	case config.ReactionQuarantine:
+		if entry.DryRun {
+			logger.Infof("docker event: dry-run mode active, server quarantine. %s", message)
+			break
+		}
+		notify.SendAsync(serverCfg, notifyEvent)
+		resultCounter.quarantineServer(serverCfg, message)
+		entry.Executed = true
+	case config.ReactionRestart:
+		if entry.DryRun {
+			logger.Infof("docker event: dry-run mode active, server restart. %s", message)
+			break
+		}
+		notify.SendAsync(serverCfg, notifyEvent)
+		item := &QueuedAction{
+			Server: serverName,
+			Action: reaction,
+			Source: SourceDockerEvent,
+			Reason: fmt.Sprintf("docker %s event", eventType),
+		}
+		queued := restartQueue.Enqueue(item, func() {
+			runEntry := entry
+			if !resultCounter.allowRestart(serverCfg, item.Reason) {
+				runEntry.Decision = DecisionRestartDenied
+				runEntry.Error = "restart budget used up, server is quarantined"
+				resultCounter.journal.Append(runEntry)
+				return
+			}
+			logger.Infof("docker event: restarting server. %s", message)
+			resultCounter.openIncident(serverCfg, "The server went down unexpectedly and is being restarted.", statuspage.IncidentIdentified, statuspage.ComponentMajorOutage)
+			restartsTotal.WithLabelValues(serverName, "docker_event").Inc()
+			runEntry.Executed = true
 			if err := server.Restart(serverCfg); err != nil {
-				return err
+				logger.Errorf("docker event: failed to restart server. %+v", err)
+				runEntry.Error = err.Error()
 			}
+			resultCounter.journal.Append(runEntry)
+		})
+		if !queued {
+			logger.Infof("docker event: restart already queued or running. %s", message)
+			entry.Decision = DecisionDeduplicated
+			break
 		}
-	default:
-		log.WithField("event_action", eventAction).Debugf("docker event: event isn't of our concern (not of type 'die')")
+		logger.Infof("docker event: queued server restart. %s", message)
+		entry.Decision = DecisionQueued
+	}
+
+	resultCounter.journal.Append(entry)
+	if entry.Error != "" && entry.Decision == DecisionAction {
+		return fmt.Errorf("docker event: %s reaction %s failed. %s", eventType, reaction, entry.Error)
 	}
 	return nil
 }
+
+func dockerNotifyEventType(eventType string) string {
+	switch eventType {
+	case config.DockerEventDie:
+		return notify.EventDockerDie
+	case config.DockerEventOOM:
+		return notify.EventDockerOOM
+	case config.DockerEventUnhealthy:
+		return notify.EventDockerUnhealthy
+	case config.DockerEventKill:
+		return notify.EventDockerKill
+	case config.DockerEventCrashLoop:
+		return notify.EventCrashLoop
+	}
+	return eventType
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/spf13/viper"
+)
+
+func TestDockerEventWatcherHandle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "srcds_controller_docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	viper.Set("dry-run", true)
+	defer viper.Set("dry-run", nil)
+	journal := NewJournal(filepath.Join(dir, "journal.jsonl"))
+	resultCounter.journal = journal
+	defer func() { resultCounter.journal = nil }()
+
+	serverCfg := &config.Config{
+		Server: &config.Server{
+			Name:    "server1",
+			Enabled: true,
+		},
+		Checker: &config.Checker{
+			DockerEvents: &config.DockerEvents{
+				Reactions: map[string]string{
+					config.DockerEventDie:       config.ReactionRestart,
+					config.DockerEventKill:      config.ReactionLog,
+					config.DockerEventUnhealthy: config.ReactionNotify,
+					config.DockerEventCrashLoop: config.ReactionQuarantine,
+				},
+				CrashLoopCount:  3,
+				CrashLoopWindow: 10 * time.Minute,
+			},
+		},
+	}
+
+	w := newDockerEventWatcher()
+	now := time.Now()
+	newEvent := func(action string, offset time.Duration) events.Message {
+		return events.Message{
+			Action:   action,
+			TimeNano: now.Add(offset).UnixNano(),
+			Actor: events.Actor{
+				Attributes: map[string]string{
+					"name":     "game-server1",
+					"exitCode": "139",
+				},
+			},
+		}
+	}
+
+	// Stop (kill followed by die) isn't a crash
+	for _, event := range []events.Message{
+		newEvent("kill", 0),
+		newEvent("die", time.Second),
+		// Crashes, the third one is a crash loop
+		newEvent("die", time.Minute),
+		newEvent("die", 2*time.Minute),
+		newEvent("die", 3*time.Minute),
+		newEvent("health_status: unhealthy", 4*time.Minute),
+	} {
+		if err := w.handle(serverCfg, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := journal.Read(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		event    string
+		decision string
+		action   string
+	}{
+		{config.DockerEventKill, DecisionAction, config.ReactionLog},
+		{config.DockerEventDie, DecisionIntentionalStop, ""},
+		{config.DockerEventDie, DecisionAction, config.ReactionRestart},
+		{config.DockerEventDie, DecisionAction, config.ReactionRestart},
+		{config.DockerEventCrashLoop, DecisionAction, config.ReactionQuarantine},
+		{config.DockerEventUnhealthy, DecisionAction, config.ReactionNotify},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d journal entries, got %d", len(expected), len(entries))
+	}
+	for i, exp := range expected {
+		if entries[i].Event != exp.event || entries[i].Decision != exp.decision || entries[i].Action != exp.action {
+			t.Errorf("entry %d: expected %+v, got event %s decision %s action %s", i, exp, entries[i].Event, entries[i].Decision, entries[i].Action)
+		}
+	}
+}
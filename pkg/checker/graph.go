@@ -0,0 +1,385 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/actions"
+	"github.com/galexrt/srcds_controller/pkg/checks"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/server"
+	log "github.com/sirupsen/logrus"
+)
+
+// checkNode a check of a server in the dependency graph
+type checkNode struct {
+	check config.Check
+	// run is nil for composite checks
+	run checks.Check
+	// needs IDs of the checks which must have been run before this check
+	needs []string
+	// schedule is nil for checks run in an interval
+	schedule *config.CronSchedule
+}
+
+// serverChecks the checks of a server ordered into levels by their
+// dependencies, checks in the same level don't depend on each other.
+type serverChecks struct {
+	server *config.Config
+	levels [][]*checkNode
+	// gracePeriod if any of the checks has a startup grace period
+	gracePeriod bool
+
+	mutex sync.Mutex
+	last  map[string]checks.Result
+	// due time each check is run next at
+	due map[string]time.Time
+}
+
+// newServerChecks create the checks of a server and order them by their
+// dependencies (`dependsOn` and composite checks)
+func newServerChecks(server *config.Config) (*serverChecks, error) {
+	nodes := map[string]*checkNode{}
+	for _, check := range server.Server.Checks {
+		node := &checkNode{
+			check: check,
+			needs: append([]string{}, check.DependsOn...),
+		}
+		if check.Name == config.CompositeCheckName {
+			if check.Composite == nil {
+				return nil, fmt.Errorf("composite check %s on server %s has no checks given", check.GetID(), server.Server.Name)
+			}
+			node.needs = append(node.needs, check.Composite.Checks...)
+		} else {
+			run, err := checks.New(check, server)
+			if err != nil {
+				return nil, err
+			}
+			node.run = run
+		}
+		if check.Schedule != "" {
+			schedule, err := config.ParseCronSchedule(check.Schedule)
+			if err != nil {
+				return nil, fmt.Errorf("check %s on server %s. %w", check.GetID(), server.Server.Name, err)
+			}
+			node.schedule = schedule
+		}
+		if err := actions.Validate(check.Limit); err != nil {
+			return nil, fmt.Errorf("check %s on server %s. %w", check.GetID(), server.Server.Name, err)
+		}
+		nodes[check.GetID()] = node
+	}
+
+	for id, node := range nodes {
+		for _, need := range node.needs {
+			if _, ok := nodes[need]; !ok {
+				return nil, fmt.Errorf("check %s on server %s depends on unknown check %s", id, server.Server.Name, need)
+			}
+		}
+	}
+
+	// Kahn's algorithm, each iteration is one level of checks
+	done := map[string]bool{}
+	levels := [][]*checkNode{}
+	for len(done) < len(nodes) {
+		level := []*checkNode{}
+		for id, node := range nodes {
+			if done[id] {
+				continue
+			}
+			ready := true
+			for _, need := range node.needs {
+				if !done[need] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				level = append(level, node)
+			}
+		}
+		if len(level) == 0 {
+			pending := []string{}
+			for id := range nodes {
+				if !done[id] {
+					pending = append(pending, id)
+				}
+			}
+			sort.Strings(pending)
+			return nil, fmt.Errorf("dependency cycle between checks %s on server %s", strings.Join(pending, ", "), server.Server.Name)
+		}
+		sort.Slice(level, func(i, j int) bool {
+			return level[i].check.GetID() < level[j].check.GetID()
+		})
+		for _, node := range level {
+			done[node.check.GetID()] = true
+		}
+		levels = append(levels, level)
+	}
+
+	gracePeriod := false
+	for _, check := range server.Server.Checks {
+		if check.GetStartupGracePeriod(server.Checker) > 0 {
+			gracePeriod = true
+		}
+	}
+
+	return &serverChecks{
+		server:      server,
+		levels:      levels,
+		gracePeriod: gracePeriod,
+		last:        map[string]checks.Result{},
+		due:         map[string]time.Time{},
+	}, nil
+}
+
+// tick run the due checks of the server level by level. Checks of which a
+// dependency isn't healthy are skipped and composite checks are evaluated
+// from the results of their checks when any of them has been run. The
+// startedAt time is used to mark results which are in their startup grace
+// period.
+func (s *serverChecks) tick(ctx context.Context, startedAt time.Time) []Result {
+	results := []Result{}
+	now := time.Now()
+	ran := map[string]bool{}
+
+	for _, level := range s.levels {
+		levelNodes := []*checkNode{}
+		for _, node := range level {
+			if s.isDue(node, now, ran) {
+				levelNodes = append(levelNodes, node)
+			}
+		}
+		levelResults := make([]checks.Result, len(levelNodes))
+
+		wg := sync.WaitGroup{}
+		for i, node := range levelNodes {
+			if unhealthy := s.unhealthyDependencies(node.check); len(unhealthy) > 0 {
+				levelResults[i] = checks.Skipped("dependencies not healthy: %s", strings.Join(unhealthy, ", "))
+				continue
+			}
+			if node.run == nil {
+				levelResults[i] = s.evaluateComposite(node.check)
+				continue
+			}
+
+			wg.Add(1)
+			go func(i int, node *checkNode) {
+				defer wg.Done()
+				log.WithField("server", s.server.Server.Name).Debugf("running check %s", node.check.GetID())
+				startTime := time.Now()
+				levelResults[i] = s.runCheck(ctx, node)
+				levelResults[i].Latency = time.Since(startTime)
+			}(i, node)
+		}
+		wg.Wait()
+
+		s.mutex.Lock()
+		for i, node := range levelNodes {
+			ran[node.check.GetID()] = true
+			s.last[node.check.GetID()] = levelResults[i]
+			if node.run != nil {
+				s.due[node.check.GetID()] = s.nextDue(node, time.Now())
+			}
+			gracePeriod := node.check.GetStartupGracePeriod(s.server.Checker)
+			results = append(results, Result{
+				Check:       node.check,
+				Server:      s.server,
+				Return:      levelResults[i],
+				GracePeriod: !startedAt.IsZero() && time.Since(startedAt) < gracePeriod,
+			})
+		}
+		s.mutex.Unlock()
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return results
+}
+
+// runCheck run the check with its timeout, a check which doesn't return in
+// time is counted as failed
+func (s *serverChecks) runCheck(ctx context.Context, node *checkNode) checks.Result {
+	timeout := node.check.GetTimeout(s.server.Checker)
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resultCh := make(chan checks.Result, 1)
+	go func() {
+		resultCh <- node.run.Run(checkCtx, s.server)
+	}()
+
+	select {
+	case result := <-resultCh:
+		return result
+	case <-checkCtx.Done():
+		if ctx.Err() != nil {
+			return checks.Result{
+				Status:  checks.StatusUnknown,
+				Message: "checker stopped",
+			}
+		}
+		return checks.Critical("check timed out after %s", timeout)
+	}
+}
+
+// isDue if the check is due to run, composite checks are due when any of
+// their checks has been run
+func (s *serverChecks) isDue(node *checkNode, now time.Time, ran map[string]bool) bool {
+	if node.run == nil && node.check.Composite != nil {
+		for _, id := range node.check.Composite.Checks {
+			if ran[id] {
+				return true
+			}
+		}
+		return false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return !now.Before(s.due[node.check.GetID()])
+}
+
+// nextDue return the time the check is run next at, either from its
+// schedule or its interval plus the checker splay
+func (s *serverChecks) nextDue(node *checkNode, now time.Time) time.Time {
+	if node.schedule != nil {
+		if next := node.schedule.Next(now); !next.IsZero() {
+			return next
+		}
+		log.WithField("server", s.server.Server.Name).Warnf("schedule %q of check %s never matches, falling back to the interval", node.check.Schedule, node.check.GetID())
+	}
+	var splay time.Duration
+	if s.server.Checker != nil && s.server.Checker.Splay != nil {
+		splay = calculateTimeSplay(s.server.Checker.Splay.Start, s.server.Checker.Splay.End)
+	}
+	return now.Add(node.check.GetInterval(s.server.Checker) + splay)
+}
+
+func (s *serverChecks) unhealthyDependencies(check config.Check) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	unhealthy := []string{}
+	for _, dep := range check.DependsOn {
+		if result, ok := s.last[dep]; !ok || !result.Healthy() {
+			unhealthy = append(unhealthy, dep)
+		}
+	}
+	return unhealthy
+}
+
+func (s *serverChecks) evaluateComposite(check config.Check) checks.Result {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	healthy := []string{}
+	failed := []string{}
+	unknown := []string{}
+	warning := false
+	for _, id := range check.Composite.Checks {
+		result, ok := s.last[id]
+		switch {
+		case !ok || result.Status == checks.StatusUnknown || result.Status == checks.StatusSkipped:
+			unknown = append(unknown, id)
+		case result.Failed():
+			failed = append(failed, id)
+		default:
+			if result.Status == checks.StatusWarning {
+				warning = true
+			}
+			healthy = append(healthy, id)
+		}
+	}
+
+	switch check.Composite.Mode {
+	case config.CompositeModeAny:
+		if len(healthy) > 0 {
+			return checks.OK("healthy checks: %s", strings.Join(healthy, ", "))
+		}
+		if len(unknown) > 0 {
+			return checks.Result{
+				Status:  checks.StatusUnknown,
+				Message: fmt.Sprintf("no healthy checks, unknown checks: %s", strings.Join(unknown, ", ")),
+			}
+		}
+		return checks.Critical("no healthy checks, failed checks: %s", strings.Join(failed, ", "))
+	default:
+		if len(failed) > 0 {
+			return checks.Critical("failed checks: %s", strings.Join(failed, ", "))
+		}
+		if len(unknown) > 0 {
+			return checks.Result{
+				Status:  checks.StatusUnknown,
+				Message: fmt.Sprintf("unknown checks: %s", strings.Join(unknown, ", ")),
+			}
+		}
+		if warning {
+			return checks.Warning("all checks healthy, some with warnings")
+		}
+		return checks.OK("all checks healthy")
+	}
+}
+
+// startedAt return the time the server container has been started at, only
+// looked up when any of the checks has a startup grace period
+func (s *serverChecks) startedAt() time.Time {
+	if !s.gracePeriod {
+		return time.Time{}
+	}
+	startedAt, err := server.StartedAt(s.server)
+	if err != nil {
+		log.WithField("server", s.server.Server.Name).Errorf("failed to get server container start time. %+v", err)
+		return time.Time{}
+	}
+	return startedAt
+}
+
+// getNextRun return the earliest time any check of the server is due at
+func (s *serverChecks) getNextRun() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var nextRun time.Time
+	for _, level := range s.levels {
+		for _, node := range level {
+			due, ok := s.due[node.check.GetID()]
+			if !ok || due.IsZero() {
+				continue
+			}
+			if nextRun.IsZero() || due.Before(nextRun) {
+				nextRun = due
+			}
+		}
+	}
+	return nextRun
+}
+
+// getCheckNextRun return the time the check is due at next
+func (s *serverChecks) getCheckNextRun(checkID string) time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.due[checkID]
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/checks"
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+type staticCheck struct {
+	status checks.Status
+}
+
+func (c *staticCheck) Run(ctx context.Context, srv *config.Config) checks.Result {
+	return checks.Result{Status: c.status}
+}
+
+// sleepCheck ignores the context to test the check timeout
+type sleepCheck struct{}
+
+func (c *sleepCheck) Run(ctx context.Context, srv *config.Config) checks.Result {
+	time.Sleep(time.Second)
+	return checks.OK("woke up")
+}
+
+func init() {
+	checks.Register("sleep", func(opts config.CheckOpts) (checks.Check, error) {
+		return &sleepCheck{}, nil
+	})
+	checks.Register("static", func(opts config.CheckOpts) (checks.Check, error) {
+		c := &staticCheck{}
+		if err := c.status.UnmarshalText([]byte(opts["status"])); err != nil {
+			return nil, err
+		}
+		return c, nil
+	})
+}
+
+func staticCheckCfg(id string, status string, dependsOn ...string) config.Check {
+	return config.Check{
+		ID:        id,
+		Name:      "static",
+		Opts:      config.CheckOpts{"status": status},
+		DependsOn: dependsOn,
+	}
+}
+
+func TestServerChecksTick(t *testing.T) {
+	srv := &config.Config{
+		Server: &config.Server{
+			Name: "server1",
+			Checks: []config.Check{
+				staticCheckCfg("container", "OK"),
+				staticCheckCfg("rcon", "CRITICAL", "container"),
+				staticCheckCfg("actioreactio", "OK", "rcon"),
+				staticCheckCfg("players", "WARNING", "container"),
+				{
+					ID:   "alive",
+					Name: config.CompositeCheckName,
+					Composite: &config.Composite{
+						Mode:   config.CompositeModeAny,
+						Checks: []string{"rcon", "players"},
+					},
+				},
+				{
+					ID:   "all",
+					Name: config.CompositeCheckName,
+					Composite: &config.Composite{
+						Mode:   config.CompositeModeAll,
+						Checks: []string{"rcon", "players"},
+					},
+				},
+			},
+		},
+	}
+
+	srvChecks, err := newServerChecks(srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]checks.Status{
+		"container":    checks.StatusOK,
+		"rcon":         checks.StatusCritical,
+		"actioreactio": checks.StatusSkipped,
+		"players":      checks.StatusWarning,
+		"alive":        checks.StatusOK,
+		"all":          checks.StatusCritical,
+	}
+	results := srvChecks.tick(context.Background(), time.Time{})
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+	for _, result := range results {
+		if status := expected[result.Check.GetID()]; result.Return.Status != status {
+			t.Errorf("check %s: expected status %s, got %s", result.Check.GetID(), status, result.Return.Status)
+		}
+	}
+}
+
+func TestServerChecksCycle(t *testing.T) {
+	srv := &config.Config{
+		Server: &config.Server{
+			Name: "server1",
+			Checks: []config.Check{
+				staticCheckCfg("a", "OK", "b"),
+				staticCheckCfg("b", "OK", "a"),
+			},
+		},
+	}
+
+	if _, err := newServerChecks(srv); err == nil {
+		t.Fatal("expected error for dependency cycle")
+	}
+}
+
+func TestServerChecksDue(t *testing.T) {
+	fast := staticCheckCfg("fast", "OK")
+	slow := staticCheckCfg("slow", "OK")
+	slow.Interval = time.Hour
+	srv := &config.Config{
+		Server: &config.Server{
+			Name:   "server1",
+			Checks: []config.Check{fast, slow},
+		},
+	}
+
+	srvChecks, err := newServerChecks(srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results := srvChecks.tick(context.Background(), time.Time{}); len(results) != 2 {
+		t.Fatalf("expected both checks to run on the first tick, got %d results", len(results))
+	}
+	if results := srvChecks.tick(context.Background(), time.Time{}); len(results) != 0 {
+		t.Fatalf("expected no checks to be due, got %d results", len(results))
+	}
+
+	srvChecks.due["fast"] = time.Now().Add(-time.Second)
+	results := srvChecks.tick(context.Background(), time.Time{})
+	if len(results) != 1 || results[0].Check.GetID() != "fast" {
+		t.Fatalf("expected only the fast check to run, got %+v", results)
+	}
+	if nextRun := srvChecks.getNextRun(); nextRun != srvChecks.due["fast"] {
+		t.Errorf("expected next run to be the fast check due time, got %s", nextRun)
+	}
+}
+
+func TestServerChecksTimeout(t *testing.T) {
+	srv := &config.Config{
+		Server: &config.Server{
+			Name: "server1",
+			Checks: []config.Check{
+				{
+					ID:      "sleep",
+					Name:    "sleep",
+					Timeout: 10 * time.Millisecond,
+				},
+			},
+		},
+	}
+
+	srvChecks, err := newServerChecks(srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := srvChecks.tick(context.Background(), time.Time{})
+	if len(results) != 1 || results[0].Return.Status != checks.StatusCritical {
+		t.Fatalf("expected the check to time out, got %+v", results)
+	}
+}
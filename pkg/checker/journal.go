@@ -0,0 +1,225 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/checks"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DecisionAction an action has been run (or simulated in dry-run mode)
+	DecisionAction = "action"
+	// DecisionGracePeriod the limit has been reached during the startup grace period
+	DecisionGracePeriod = "grace_period"
+	// DecisionQuarantined no actions run because the server is quarantined
+	DecisionQuarantined = "quarantined"
+	// DecisionQueued the restart has been queued and is run from the restart queue
+	DecisionQueued = "queued"
+	// DecisionDeduplicated the restart has not been queued as one is already queued or running for the server
+	DecisionDeduplicated = "deduplicated"
+	// DecisionRestartDenied the restart budget has been used up
+	DecisionRestartDenied = "restart_denied"
+	// DecisionSilenced no actions run because the server is silenced or in a maintenance window
+	DecisionSilenced = "silenced"
+	// DecisionIntentionalStop the server container has been stopped on purpose, not a crash
+	DecisionIntentionalStop = "intentional_stop"
+)
+
+const (
+	// SourceChecker decision made because of a check limit
+	SourceChecker = "checker"
+	// SourceDockerEvent decision made because of a Docker event
+	SourceDockerEvent = "docker_event"
+)
+
+// JournalEntry a decision made by the checker
+type JournalEntry struct {
+	Time   time.Time `json:"time"`
+	Server string    `json:"server"`
+	Source string    `json:"source"`
+	Check  string    `json:"check,omitempty"`
+	// Event Docker event type for decisions from Docker events
+	Event    string `json:"event,omitempty"`
+	Decision string `json:"decision"`
+	Action   string `json:"action,omitempty"`
+	DryRun   bool   `json:"dryRun"`
+	// Executed if the action has actually been run
+	Executed bool   `json:"executed"`
+	Error    string `json:"error,omitempty"`
+	// Result, Counter and Limit which triggered the decision
+	Result  *checks.Result `json:"result,omitempty"`
+	Counter *ResultCounter `json:"counter,omitempty"`
+	Limit   *config.Limit  `json:"limit,omitempty"`
+}
+
+// Journal append only JSON lines file of the decisions made by the checker
+type Journal struct {
+	sync.Mutex
+	path string
+}
+
+// NewJournal return a new journal writing to the given path, an empty path
+// disables the journal
+func NewJournal(path string) *Journal {
+	return &Journal{
+		path: path,
+	}
+}
+
+// Append append the entry to the journal file. The file is opened for each
+// entry so it can be rotated without restarting the checker.
+func (j *Journal) Append(entry JournalEntry) {
+	if j == nil || j.path == "" {
+		return
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	out, err := json.Marshal(entry)
+	if err != nil {
+		log.Errorf("failed to marshal journal entry. %+v", err)
+		return
+	}
+
+	j.Lock()
+	defer j.Unlock()
+
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		log.Errorf("failed to open journal file %s. %+v", j.path, err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(out, '\n')); err != nil {
+		log.Errorf("failed to write to journal file %s. %+v", j.path, err)
+	}
+}
+
+// Read return the last entries of the journal, optionally filtered by the
+// given servers. A limit of 0 returns all entries.
+func (j *Journal) Read(servers map[string]bool, limit int) ([]*JournalEntry, error) {
+	entries := []*JournalEntry{}
+	if j == nil || j.path == "" {
+		return entries, nil
+	}
+
+	j.Lock()
+	defer j.Unlock()
+
+	f, err := os.Open(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		entry := &JournalEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			log.Warnf("skipping invalid journal entry in %s. %+v", j.path, err)
+			continue
+		}
+		if len(servers) > 0 && !servers[entry.Server] {
+			continue
+		}
+		entries = append(entries, entry)
+		if limit > 0 && len(entries) > limit {
+			entries = entries[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// WriteJournal write the journal entries as a table or as JSON lines
+func WriteJournal(out io.Writer, entries []*JournalEntry, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(out)
+		for _, entry := range entries {
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 1, 0, 1, ' ', tabwriter.Debug)
+	fmt.Fprintln(w, "Time\tServer\tSource\tCheck / Event\tDecision\tAction\tExecuted\tFailures\tLimit\tResult\tError")
+	for _, entry := range entries {
+		failures := "-"
+		if entry.Counter != nil {
+			failures = fmt.Sprintf("%d in %s", entry.Counter.Count, entry.Counter.LastTime.Sub(entry.Counter.FirstTime).Round(time.Second))
+		}
+		limit := "-"
+		if entry.Limit != nil {
+			limit = fmt.Sprintf("count %d / after %s", entry.Limit.Count, entry.Limit.After)
+		}
+		result := "-"
+		if entry.Result != nil {
+			result = fmt.Sprintf("%s: %s", entry.Result.Status, entry.Result.Message)
+		}
+		executed := "yes"
+		if !entry.Executed {
+			executed = "no"
+			if entry.DryRun {
+				executed = "no (dry-run)"
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Time.Local().Format("2006-01-02 15:04:05"),
+			entry.Server,
+			entry.Source,
+			orDash(entry.Check+entry.Event),
+			entry.Decision,
+			orDash(entry.Action),
+			executed,
+			failures,
+			limit,
+			result,
+			orDash(entry.Error),
+		)
+	}
+	return w.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
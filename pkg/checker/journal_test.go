@@ -0,0 +1,115 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/galexrt/srcds_controller/pkg/checks"
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "srcds_controller_journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	journal := NewJournal(filepath.Join(dir, "journal.jsonl"))
+	result := checks.Critical("rcon connection refused")
+	for _, serverName := range []string{"server1", "server2", "server1", "server1"} {
+		journal.Append(JournalEntry{
+			Server:   serverName,
+			Source:   SourceChecker,
+			Check:    "rcon",
+			Decision: DecisionAction,
+			Action:   "restart",
+			DryRun:   true,
+			Result:   &result,
+			Counter:  &ResultCounter{Count: 3},
+			Limit:    &config.Limit{Count: 3},
+		})
+	}
+
+	entries, err := journal.Read(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 journal entries, got %d", len(entries))
+	}
+	if entries[0].Time.IsZero() || entries[0].Result == nil || entries[0].Result.Message != result.Message {
+		t.Errorf("unexpected journal entry %+v", entries[0])
+	}
+
+	entries, err = journal.Read(map[string]bool{"server1": true}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 journal entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.Server != "server1" {
+			t.Errorf("expected only server1 entries, got %s", entry.Server)
+		}
+	}
+
+	// A journal without path is disabled
+	NewJournal("").Append(JournalEntry{Server: "server1"})
+	if entries, err := NewJournal("").Read(nil, 0); err != nil || len(entries) != 0 {
+		t.Errorf("expected disabled journal to be empty, got %d entries (err: %v)", len(entries), err)
+	}
+}
+
+func TestRunActionJournalExecuted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "srcds_controller_journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := NewResultServerList()
+	r.journal = NewJournal(filepath.Join(dir, "journal.jsonl"))
+	serverCfg := &config.Config{
+		Server: &config.Server{
+			Name: "server1",
+		},
+	}
+	check := config.Check{
+		Name: "dummy",
+		Limit: &config.Limit{
+			Count:   1,
+			Actions: []string{"doesnotexist"},
+		},
+	}
+
+	if errs := r.runAction(check, serverCfg, checks.Critical("failed"), ResultCounter{Count: 1}); errs["doesnotexist"] == "" {
+		t.Fatal("expected error for unknown action")
+	}
+	entries, err := r.journal.Read(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Executed || entries[0].Error == "" {
+		t.Fatalf("expected one not executed journal entry with error, got %+v", entries)
+	}
+}
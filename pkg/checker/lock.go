@@ -0,0 +1,112 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Lock exclusive flock based lock so only one checker is active at a time.
+// The lock is released by the kernel when the process exits.
+type Lock struct {
+	path string
+	file *os.File
+}
+
+// NewLock return a new lock for the given lock file path
+func NewLock(path string) *Lock {
+	return &Lock{
+		path: path,
+	}
+}
+
+// TryLock try to acquire the lock without blocking, returns false when the
+// lock is held by another process
+func (l *Lock) TryLock() (bool, error) {
+	if l.file != nil {
+		return true, nil
+	}
+
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0660)
+	if err != nil {
+		return false, fmt.Errorf("failed to open checker lock file %s. %w", l.path, err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if err == syscall.EWOULDBLOCK {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to lock checker lock file %s. %w", l.path, err)
+	}
+
+	// Write the PID into the lock file to show who is holding the lock
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	l.file = f
+	return true, nil
+}
+
+// Wait wait until the lock has been acquired, returns false when the stop
+// channel has been closed before
+func (l *Lock) Wait(stopCh <-chan struct{}, interval time.Duration) (bool, error) {
+	for {
+		locked, err := l.TryLock()
+		if err != nil || locked {
+			return locked, err
+		}
+		select {
+		case <-stopCh:
+			return false, nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Unlock release the lock
+func (l *Lock) Unlock() error {
+	if l.file == nil {
+		return nil
+	}
+	defer func() {
+		l.file.Close()
+		l.file = nil
+	}()
+	if err := syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN); err != nil {
+		return err
+	}
+	log.Debugf("released checker lock %s", l.path)
+	return nil
+}
+
+// Holder return the PID of the process holding the lock as written to the
+// lock file, empty when unknown
+func (l *Lock) Holder() string {
+	out, err := ioutil.ReadFile(l.path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
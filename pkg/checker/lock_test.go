@@ -0,0 +1,71 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "srcds_controller_lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	lockFile := filepath.Join(dir, "checker.lock")
+
+	first := NewLock(lockFile)
+	locked, err := first.TryLock()
+	if err != nil || !locked {
+		t.Fatalf("expected first lock to be acquired, got %v, %v", locked, err)
+	}
+	if holder := first.Holder(); holder != strconv.Itoa(os.Getpid()) {
+		t.Errorf("expected lock holder %d, got %q", os.Getpid(), holder)
+	}
+
+	second := NewLock(lockFile)
+	locked, err = second.TryLock()
+	if err != nil || locked {
+		t.Fatalf("expected second lock to not be acquired, got %v, %v", locked, err)
+	}
+
+	stopCh := make(chan struct{})
+	done := make(chan bool)
+	go func() {
+		locked, _ := second.Wait(stopCh, 10*time.Millisecond)
+		done <- locked
+	}()
+
+	if err := first.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case locked := <-done:
+		if !locked {
+			t.Error("expected standby lock to take over")
+		}
+	case <-time.After(5 * time.Second):
+		close(stopCh)
+		t.Fatal("standby lock did not take over")
+	}
+	second.Unlock()
+}
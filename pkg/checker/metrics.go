@@ -0,0 +1,192 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"strings"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const metricsNamespace = "srcds_controller"
+const metricsSubsystem = "checker"
+
+var (
+	checkExecutionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "check_executions_total",
+		Help:      "Total number of check executions by status.",
+	}, []string{"server", "check", "status"})
+	checkFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "check_failures_total",
+		Help:      "Total number of failed check executions.",
+	}, []string{"server", "check"})
+	checkDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "check_duration_seconds",
+		Help:      "Duration of check executions.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"server", "check"})
+	actionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "actions_total",
+		Help:      "Total number of actions executed by type.",
+	}, []string{"server", "action"})
+	actionErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "action_errors_total",
+		Help:      "Total number of failed actions by type.",
+	}, []string{"server", "action"})
+	restartsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "restarts_total",
+		Help:      "Total number of server restarts triggered by the checker.",
+	}, []string{"server", "reason"})
+	quarantinesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "quarantines_total",
+		Help:      "Total number of times a server has been quarantined after using up its restart budget.",
+	}, []string{"server"})
+	dockerEventsReconnectsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "docker_events_reconnects_total",
+		Help:      "Total number of Docker event stream reconnects.",
+	})
+
+	resultCounterCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "result_counter_count"),
+		"Current failure count of the check result counter.",
+		[]string{"server", "check"}, nil,
+	)
+	quarantinedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "quarantined"),
+		"If the server is quarantined (1) after using up its restart budget.",
+		[]string{"server"}, nil,
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		checkExecutionsTotal,
+		checkFailuresTotal,
+		checkDurationSeconds,
+		actionsTotal,
+		actionErrorsTotal,
+		restartsTotal,
+		quarantinesTotal,
+		dockerEventsReconnectsTotal,
+		resultCounterCollector{},
+	)
+}
+
+// resultCounterCollector exposes the current result counters
+type resultCounterCollector struct{}
+
+// Describe implements prometheus.Collector
+func (c resultCounterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- resultCounterCountDesc
+	ch <- quarantinedDesc
+}
+
+// Collect implements prometheus.Collector
+func (c resultCounterCollector) Collect(ch chan<- prometheus.Metric) {
+	resultCounter.RLock()
+	defer resultCounter.RUnlock()
+
+	for serverName, counters := range resultCounter.results {
+		for checkID, counter := range counters {
+			ch <- prometheus.MustNewConstMetric(resultCounterCountDesc, prometheus.GaugeValue, float64(counter.Count), serverName, checkID)
+		}
+	}
+	for serverName := range resultCounter.quarantine {
+		ch <- prometheus.MustNewConstMetric(quarantinedDesc, prometheus.GaugeValue, 1, serverName)
+	}
+}
+
+// serverMetricVec metric vector with a server label
+type serverMetricVec interface {
+	prometheus.Collector
+	Delete(labels prometheus.Labels) bool
+}
+
+// deleteServerMetrics delete the series of a removed server, otherwise they
+// would be exported with their last value forever
+func deleteServerMetrics(serverName string) {
+	for _, vec := range []serverMetricVec{
+		checkExecutionsTotal,
+		checkFailuresTotal,
+		checkDurationSeconds,
+		actionsTotal,
+		actionErrorsTotal,
+		restartsTotal,
+		quarantinesTotal,
+	} {
+		for _, labels := range serverSeries(vec, serverName) {
+			vec.Delete(labels)
+		}
+	}
+}
+
+// serverSeries return the label sets of the series of the server in the
+// metric vector
+func serverSeries(vec serverMetricVec, serverName string) []prometheus.Labels {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		vec.Collect(ch)
+		close(ch)
+	}()
+
+	series := []prometheus.Labels{}
+	for metric := range ch {
+		out := &dto.Metric{}
+		if err := metric.Write(out); err != nil {
+			continue
+		}
+		labels := prometheus.Labels{}
+		for _, label := range out.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		if labels["server"] == serverName {
+			series = append(series, labels)
+		}
+	}
+	return series
+}
+
+func observeResult(result Result) {
+	serverName := result.Server.Server.Name
+	checkID := result.Check.GetID()
+
+	checkExecutionsTotal.WithLabelValues(serverName, checkID, strings.ToLower(result.Return.Status.String())).Inc()
+	if result.Return.Failed() {
+		checkFailuresTotal.WithLabelValues(serverName, checkID).Inc()
+	}
+	if result.Check.Name != config.CompositeCheckName && result.Return.Latency > 0 {
+		checkDurationSeconds.WithLabelValues(serverName, checkID).Observe(result.Return.Latency.Seconds())
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"testing"
+
+	"github.com/galexrt/srcds_controller/pkg/checks"
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+func TestDeleteServerMetrics(t *testing.T) {
+	for _, serverName := range []string{"metrics1", "metrics2"} {
+		observeResult(Result{
+			Check: config.Check{
+				Name: "dummy",
+			},
+			Server: &config.Config{
+				Server: &config.Server{
+					Name: serverName,
+				},
+			},
+			Return: checks.Critical("failed"),
+		})
+		restartsTotal.WithLabelValues(serverName, "check").Inc()
+	}
+	if series := serverSeries(checkFailuresTotal, "metrics1"); len(series) != 1 || series[0]["check"] != "dummy" {
+		t.Fatalf("expected one check failures series for metrics1, got %v", series)
+	}
+
+	deleteServerMetrics("metrics1")
+	for _, vec := range []serverMetricVec{checkExecutionsTotal, checkFailuresTotal, restartsTotal} {
+		if series := serverSeries(vec, "metrics1"); len(series) != 0 {
+			t.Errorf("expected metrics1 series to be deleted, got %v", series)
+		}
+		if series := serverSeries(vec, "metrics2"); len(series) != 1 {
+			t.Errorf("expected metrics2 series to be kept, got %v", series)
+		}
+	}
+}
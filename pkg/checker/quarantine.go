@@ -0,0 +1,182 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/actions"
+	"github.com/galexrt/srcds_controller/pkg/checks"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/notify"
+	"github.com/galexrt/srcds_controller/pkg/statuspage"
+	log "github.com/sirupsen/logrus"
+)
+
+// Quarantine a server which has used up its restart budget, no actions are
+// run for the server until the quarantine is cleared
+type Quarantine struct {
+	Server   string      `json:"server"`
+	Since    time.Time   `json:"since"`
+	Reason   string      `json:"reason"`
+	Restarts []time.Time `json:"restarts"`
+}
+
+// Quarantined return the quarantine of the server, nil if not quarantined
+func (r *ResultServerList) Quarantined(serverName string) *Quarantine {
+	r.RLock()
+	defer r.RUnlock()
+	if q, ok := r.quarantine[serverName]; ok {
+		qCopy := *q
+		return &qCopy
+	}
+	return nil
+}
+
+// Quarantines return all quarantined servers sorted by server name
+func (r *ResultServerList) Quarantines() []*Quarantine {
+	r.RLock()
+	defer r.RUnlock()
+
+	quarantines := []*Quarantine{}
+	for _, q := range r.quarantine {
+		qCopy := *q
+		quarantines = append(quarantines, &qCopy)
+	}
+	sort.Slice(quarantines, func(i, j int) bool {
+		return quarantines[i].Server < quarantines[j].Server
+	})
+	return quarantines
+}
+
+// ClearQuarantine clear the quarantine and the restart history of the
+// server, returns false when the server isn't quarantined
+func (r *ResultServerList) ClearQuarantine(serverName string) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.restarts, serverName)
+	if _, ok := r.quarantine[serverName]; !ok {
+		return false
+	}
+	delete(r.quarantine, serverName)
+	log.WithField("server", serverName).Info("server quarantine cleared")
+	return true
+}
+
+// allowRestart record an automatic restart of the server if the restart
+// budget allows it. When the budget is used up the server is quarantined
+// and the alert actions of the budget are run.
+func (r *ResultServerList) allowRestart(serverCfg *config.Config, reason string) bool {
+	serverName := serverCfg.Server.Name
+
+	var budget *config.RestartBudget
+	if serverCfg.Checker != nil {
+		budget = serverCfg.Checker.RestartBudget
+	}
+
+	r.Lock()
+	if _, ok := r.quarantine[serverName]; ok {
+		r.Unlock()
+		log.WithField("server", serverName).Warnf("server is quarantined, not restarting (%s)", reason)
+		return false
+	}
+	if budget == nil || budget.Count <= 0 {
+		r.Unlock()
+		return true
+	}
+
+	now := time.Now()
+	restarts := []time.Time{}
+	for _, t := range r.restarts[serverName] {
+		if now.Sub(t) < budget.Window {
+			restarts = append(restarts, t)
+		}
+	}
+
+	if int64(len(restarts)) < budget.Count {
+		r.restarts[serverName] = append(restarts, now)
+		r.Unlock()
+		return true
+	}
+
+	r.restarts[serverName] = restarts
+	r.Unlock()
+
+	r.quarantineServer(serverCfg, fmt.Sprintf("restart budget of %d restarts in %s used up (%s)", budget.Count, budget.Window, reason))
+	return false
+}
+
+// quarantineServer quarantine the server, notify about it and run the alert
+// actions of the restart budget
+func (r *ResultServerList) quarantineServer(serverCfg *config.Config, reason string) {
+	serverName := serverCfg.Server.Name
+	now := time.Now()
+
+	r.Lock()
+	if _, ok := r.quarantine[serverName]; ok {
+		r.Unlock()
+		return
+	}
+	q := &Quarantine{
+		Server:   serverName,
+		Since:    now,
+		Reason:   reason,
+		Restarts: append([]time.Time{}, r.restarts[serverName]...),
+	}
+	r.quarantine[serverName] = q
+	ctx := r.ctx
+	controller := r.controller
+	r.Unlock()
+
+	quarantinesTotal.WithLabelValues(serverName).Inc()
+	log.WithField("server", serverName).Errorf("server quarantined, no further actions are run until the quarantine is cleared with `sc quarantine clear %s`. %s", serverName, q.Reason)
+
+	notify.SendAsync(serverCfg, notify.Event{
+		Type:    notify.EventQuarantine,
+		Message: q.Reason,
+	})
+	r.openIncident(serverCfg, "The server is unavailable, it failed repeatedly and is being investigated.", statuspage.IncidentInvestigating, statuspage.ComponentMajorOutage)
+
+	if serverCfg.Checker == nil || serverCfg.Checker.RestartBudget == nil {
+		return
+	}
+	budget := serverCfg.Checker.RestartBudget
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	event := actions.Event{
+		Server:     serverCfg,
+		CheckID:    "quarantine",
+		Result:     checks.Critical(q.Reason),
+		Time:       now,
+		Controller: controller,
+	}
+	for _, name := range budget.AlertActions {
+		action, err := actions.New(name, budget.AlertActionOpts)
+		if err == nil {
+			err = action.Run(ctx, event)
+		}
+		if err != nil {
+			log.WithField("server", serverName).Errorf("quarantine alert action %s failed. %+v", name, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+func TestAllowRestart(t *testing.T) {
+	r := NewResultServerList()
+	serverCfg := &config.Config{
+		Server: &config.Server{
+			Name: "server1",
+		},
+		Checker: &config.Checker{
+			RestartBudget: &config.RestartBudget{
+				Count:  2,
+				Window: time.Hour,
+			},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		if !r.allowRestart(serverCfg, "test") {
+			t.Fatalf("expected restart %d to be allowed", i+1)
+		}
+	}
+	if r.allowRestart(serverCfg, "test") {
+		t.Fatal("expected restart over the budget to be denied")
+	}
+	if q := r.Quarantined("server1"); q == nil || len(q.Restarts) != 2 {
+		t.Fatalf("expected server1 to be quarantined with 2 restarts, got %+v", q)
+	}
+	if r.allowRestart(serverCfg, "test") {
+		t.Fatal("expected restart of quarantined server to be denied")
+	}
+
+	if !r.ClearQuarantine("server1") {
+		t.Fatal("expected quarantine of server1 to be cleared")
+	}
+	if !r.allowRestart(serverCfg, "test") {
+		t.Fatal("expected restart to be allowed after clearing the quarantine")
+	}
+}
+
+func TestAllowRestartWindow(t *testing.T) {
+	r := NewResultServerList()
+	serverCfg := &config.Config{
+		Server: &config.Server{
+			Name: "server1",
+		},
+		Checker: &config.Checker{
+			RestartBudget: &config.RestartBudget{
+				Count:  1,
+				Window: time.Hour,
+			},
+		},
+	}
+
+	r.restarts["server1"] = []time.Time{time.Now().Add(-2 * time.Hour)}
+	if !r.allowRestart(serverCfg, "test") {
+		t.Fatal("expected restart to be allowed, previous restart is outside of the window")
+	}
+}
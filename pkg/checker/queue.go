@@ -0,0 +1,171 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// QueuedAction an action waiting in or running from the restart queue
+type QueuedAction struct {
+	Server string `json:"server"`
+	Action string `json:"action"`
+	// Source where the action has been queued from, see the `Source*` constants
+	Source  string    `json:"source"`
+	Reason  string    `json:"reason"`
+	Queued  time.Time `json:"queued"`
+	Running bool      `json:"running"`
+	Started time.Time `json:"started"`
+
+	run func()
+}
+
+// actionQueue runs the queued actions with a limit on how many run at the same
+// time and a minimum spacing between them. Only one action per server can be
+// queued or running at a time.
+type actionQueue struct {
+	sync.Mutex
+	queued    []*QueuedAction
+	running   map[string]*QueuedAction
+	lastStart time.Time
+	wakeCh    chan struct{}
+}
+
+var restartQueue = newActionQueue()
+
+func newActionQueue() *actionQueue {
+	return &actionQueue{
+		queued:  []*QueuedAction{},
+		running: map[string]*QueuedAction{},
+		wakeCh:  make(chan struct{}, 1),
+	}
+}
+
+// Enqueue add the action to the queue, returns false when an action for the
+// server is already queued or running
+func (q *actionQueue) Enqueue(item *QueuedAction, run func()) bool {
+	q.Lock()
+	defer q.Unlock()
+
+	if _, ok := q.running[item.Server]; ok {
+		return false
+	}
+	for _, queued := range q.queued {
+		if queued.Server == item.Server {
+			return false
+		}
+	}
+
+	item.Queued = time.Now()
+	item.run = run
+	q.queued = append(q.queued, item)
+	q.wake()
+	return true
+}
+
+// Queue return a copy of the running and queued actions, running first
+func (q *actionQueue) Queue() []QueuedAction {
+	q.Lock()
+	defer q.Unlock()
+
+	items := []QueuedAction{}
+	for _, item := range q.running {
+		items = append(items, *item)
+	}
+	for _, item := range q.queued {
+		items = append(items, *item)
+	}
+	return items
+}
+
+// Run run the queued actions until the stop channel is closed, a concurrency
+// of zero or less means no limit
+func (q *actionQueue) Run(stopCh <-chan struct{}, concurrency int, spacing time.Duration) {
+	wg := sync.WaitGroup{}
+	defer wg.Wait()
+
+	for {
+		item, wait := q.next(time.Now(), concurrency, spacing)
+		if item != nil {
+			log.WithField("server", item.Server).Infof("running queued action %s (%s)", item.Action, item.Reason)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				item.run()
+				q.done(item)
+			}()
+			continue
+		}
+
+		var timer <-chan time.Time
+		if wait > 0 {
+			timer = time.After(wait)
+		}
+		select {
+		case <-stopCh:
+			q.Lock()
+			if len(q.queued) > 0 {
+				log.Warnf("dropping %d queued action(s) on stop", len(q.queued))
+				q.queued = []*QueuedAction{}
+			}
+			q.Unlock()
+			return
+		case <-q.wakeCh:
+		case <-timer:
+		}
+	}
+}
+
+// next return the next action to run, when none can be started yet the
+// duration to wait for the spacing is returned
+func (q *actionQueue) next(now time.Time, concurrency int, spacing time.Duration) (*QueuedAction, time.Duration) {
+	q.Lock()
+	defer q.Unlock()
+
+	if len(q.queued) == 0 || (concurrency > 0 && len(q.running) >= concurrency) {
+		return nil, 0
+	}
+	if since := now.Sub(q.lastStart); since < spacing {
+		return nil, spacing - since
+	}
+
+	item := q.queued[0]
+	q.queued = q.queued[1:]
+	item.Running = true
+	item.Started = now
+	q.running[item.Server] = item
+	q.lastStart = now
+	return item, 0
+}
+
+func (q *actionQueue) done(item *QueuedAction) {
+	q.Lock()
+	defer q.Unlock()
+	delete(q.running, item.Server)
+	q.wake()
+}
+
+// wake wake up the queue runner, must be called with the lock held
+func (q *actionQueue) wake() {
+	select {
+	case q.wakeCh <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActionQueue(t *testing.T) {
+	q := newActionQueue()
+	noop := func() {}
+
+	if !q.Enqueue(&QueuedAction{Server: "server1", Action: "restart"}, noop) {
+		t.Fatal("expected server1 to be queued")
+	}
+	if q.Enqueue(&QueuedAction{Server: "server1", Action: "nicerestart"}, noop) {
+		t.Error("expected second action for server1 to be deduplicated")
+	}
+	for _, name := range []string{"server2", "server3"} {
+		if !q.Enqueue(&QueuedAction{Server: name, Action: "restart"}, noop) {
+			t.Fatalf("expected %s to be queued", name)
+		}
+	}
+
+	now := time.Now()
+	item, _ := q.next(now, 2, 10*time.Second)
+	if item == nil || item.Server != "server1" || !item.Running {
+		t.Fatalf("expected server1 to be started first, got %+v", item)
+	}
+	if q.Enqueue(&QueuedAction{Server: "server1", Action: "restart"}, noop) {
+		t.Error("expected action for running server1 to be deduplicated")
+	}
+
+	item, wait := q.next(now.Add(5*time.Second), 2, 10*time.Second)
+	if item != nil || wait != 5*time.Second {
+		t.Fatalf("expected to wait 5s for the spacing, got %+v, %s", item, wait)
+	}
+	item, _ = q.next(now.Add(10*time.Second), 2, 10*time.Second)
+	if item == nil || item.Server != "server2" {
+		t.Fatalf("expected server2 to be started, got %+v", item)
+	}
+	item, wait = q.next(now.Add(time.Minute), 2, 10*time.Second)
+	if item != nil || wait != 0 {
+		t.Fatalf("expected concurrency limit to be reached, got %+v, %s", item, wait)
+	}
+	if queue := q.Queue(); len(queue) != 3 {
+		t.Errorf("expected 3 actions in the queue, got %d", len(queue))
+	}
+
+	q.done(&QueuedAction{Server: "server1"})
+	item, _ = q.next(now.Add(time.Minute), 2, 10*time.Second)
+	if item == nil || item.Server != "server3" {
+		t.Fatalf("expected server3 to be started, got %+v", item)
+	}
+}
@@ -17,121 +17,346 @@ limitations under the License.
 package checker
 
 import (
+	"context"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/galexrt/srcds_controller/pkg/actions"
+	"github.com/galexrt/srcds_controller/pkg/checks"
 	"github.com/galexrt/srcds_controller/pkg/config"
-	"github.com/galexrt/srcds_controller/pkg/server"
+	"github.com/galexrt/srcds_controller/pkg/notify"
+	"github.com/galexrt/srcds_controller/pkg/statuspage"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
 // ResultCounter result counter to count the results success or failure
 type ResultCounter struct {
-	Count     int64
-	FirstTime time.Time
-	LastTime  time.Time
+	Count int64 `json:"count"`
+	// GraceCount failures during the startup grace period, they don't count
+	// toward the limit
+	GraceCount int64     `json:"graceCount"`
+	FirstTime  time.Time `json:"firstTime"`
+	LastTime   time.Time `json:"lastTime"`
 }
 
 // ResultServerList result counter per server list
 type ResultServerList struct {
 	sync.RWMutex
 	results map[string]map[string]*ResultCounter
+	// status last result and action per server check
+	status map[string]map[string]*CheckStatus
+	// restarts times of the automatic restarts per server in the restart
+	// budget window
+	restarts map[string][]time.Time
+	// quarantine servers which have used up their restart budget
+	quarantine map[string]*Quarantine
+	// silences maintenance silences per server
+	silences map[string]*Silence
+	// incidents IDs of the status page incidents opened by the controller per
+	// server, resolved once the server is healthy again
+	incidents map[string][]int
+
+	// ctx and controller passed to the actions, set by the checker
+	ctx        context.Context
+	controller actions.Controller
+	// journal decisions are appended to, set by the checker
+	journal *Journal
+	// stateFile the state is saved to right away on silence changes, set by
+	// the checker
+	stateFile string
 }
 
 // Result server check result
 type Result struct {
 	Check  config.Check
 	Server *config.Config
-	Return bool
+	Return checks.Result
+	// GracePeriod if the server is in the check's startup grace period,
+	// failures are recorded but don't count toward the limit
+	GracePeriod bool
 }
 
 // NewResultServerList return new result counter server lit
 func NewResultServerList() *ResultServerList {
 	return &ResultServerList{
-		results: map[string]map[string]*ResultCounter{},
+		results:    map[string]map[string]*ResultCounter{},
+		status:     map[string]map[string]*CheckStatus{},
+		restarts:   map[string][]time.Time{},
+		quarantine: map[string]*Quarantine{},
+		silences:   map[string]*Silence{},
+		incidents:  map[string][]int{},
 	}
 }
 
 // Add add a new result to the result counter server list
 func (r *ResultServerList) Add(result Result) {
+	logger := log.WithFields(log.Fields{
+		"server": result.Server.Server.Name,
+		"check":  result.Check.GetID(),
+		"status": result.Return.Status,
+	})
+
+	checkID := result.Check.GetID()
+	r.setLastResult(result)
+	observeResult(result)
+
+	switch result.Return.Status {
+	case checks.StatusSkipped:
+		logger.Debugf("check skipped, not counting result. %s", result.Return.Message)
+		return
+	case checks.StatusUnknown:
+		logger.Errorf("check errored, not counting result. %s", result.Return.Message)
+		return
+	case checks.StatusWarning:
+		logger.Warnf("check returned warning. %s", result.Return.Message)
+	case checks.StatusCritical:
+		logger.Warnf("check failed. %s", result.Return.Message)
+	default:
+		logger.Debugf("check succeeded. %s", result.Return.Message)
+	}
+
 	r.Lock()
 	if _, ok := r.results[result.Server.Server.Name]; !ok {
 		r.results[result.Server.Server.Name] = map[string]*ResultCounter{}
 	}
-	if result.Return {
-		if _, ok := r.results[result.Server.Server.Name][result.Check.Name]; ok {
-			delete(r.results[result.Server.Server.Name], result.Check.Name)
+	if !result.Return.Failed() {
+		if _, ok := r.results[result.Server.Server.Name][checkID]; ok {
+			delete(r.results[result.Server.Server.Name], checkID)
 		}
 		r.Unlock()
 		return
 	}
 	now := time.Now()
-	if _, ok := r.results[result.Server.Server.Name][result.Check.Name]; !ok {
-		r.results[result.Server.Server.Name][result.Check.Name] = &ResultCounter{
+	if _, ok := r.results[result.Server.Server.Name][checkID]; !ok {
+		r.results[result.Server.Server.Name][checkID] = &ResultCounter{
 			Count:     0,
 			FirstTime: now,
 		}
 	}
-	r.results[result.Server.Server.Name][result.Check.Name].Count++
-	r.results[result.Server.Server.Name][result.Check.Name].LastTime = now
 
 	serverCfg := result.Server
 	check := result.Check
-	counter := r.results[result.Server.Server.Name][result.Check.Name]
-	r.Unlock()
+	counter := r.results[result.Server.Server.Name][checkID]
+
+	// Failures during the startup grace period are recorded, but only the
+	// failures after it count toward the limit
+	if result.GracePeriod {
+		counter.GraceCount++
+		counter.LastTime = now
+		counterCopy := *counter
+		r.Unlock()
+
+		log.WithField("server", result.Server.Server.Name).Debugf("server %s check %s failed during startup grace period (%d failures)", serverCfg.Server.Name, checkID, counterCopy.GraceCount)
+		if check.Limit != nil && check.Limit.Count != 0 && counterCopy.GraceCount == check.Limit.Count {
+			log.WithField("server", result.Server.Server.Name).Infof("result counter would be over limit for server %s check %s, but server is in startup grace period", serverCfg.Server.Name, checkID)
+			r.journal.Append(JournalEntry{
+				Server:   serverCfg.Server.Name,
+				Source:   SourceChecker,
+				Check:    checkID,
+				Decision: DecisionGracePeriod,
+				DryRun:   viper.GetBool("dry-run"),
+				Result:   &result.Return,
+				Counter:  &counterCopy,
+				Limit:    check.Limit,
+			})
+		}
+		return
+	}
+
+	if counter.Count == 0 {
+		counter.FirstTime = now
+	}
+	counter.Count++
+	counter.LastTime = now
 
-	log.WithField("server", result.Server.Server.Name).Debugf("evaluating result counter for server %s check %s", serverCfg.Server.Name, check.Name)
+	if check.Limit == nil {
+		r.Unlock()
+		log.WithField("server", result.Server.Server.Name).Debugf("no limit for server %s check %s, nothing to do", serverCfg.Server.Name, checkID)
+		return
+	}
+
+	log.WithField("server", result.Server.Server.Name).Debugf("evaluating result counter for server %s check %s", serverCfg.Server.Name, checkID)
 	log.WithField("server", result.Server.Server.Name).Debugf("current state: count: %d/%d, time: %s - %s", counter.Count, check.Limit.Count, counter.LastTime.Sub(counter.FirstTime), check.Limit.After)
 	if (check.Limit.Count != 0 && counter.Count >= check.Limit.Count) ||
 		(check.Limit.After != 0 && counter.LastTime.Sub(counter.FirstTime) >= check.Limit.After) {
 
-		log.WithField("server", result.Server.Server.Name).Infof("result counter over limit for server %s check %s", serverCfg.Server.Name, check.Name)
+		counterCopy := *counter
+		log.WithField("server", result.Server.Server.Name).Infof("result counter over limit for server %s check %s", serverCfg.Server.Name, checkID)
 
 		counter.Count = 0
 		now := time.Now()
 		counter.FirstTime = now
 		counter.LastTime = now
+		r.Unlock()
+
+		if reason, ok := r.silenced(serverCfg); ok {
+			log.WithField("server", result.Server.Server.Name).Infof("not running actions for server %s check %s, %s", serverCfg.Server.Name, checkID, reason)
+			r.journal.Append(JournalEntry{
+				Server:   serverCfg.Server.Name,
+				Source:   SourceChecker,
+				Check:    checkID,
+				Decision: DecisionSilenced,
+				DryRun:   viper.GetBool("dry-run"),
+				Error:    reason,
+				Result:   &result.Return,
+				Counter:  &counterCopy,
+				Limit:    check.Limit,
+			})
+			return
+		}
 
-		r.runAction(check, serverCfg)
+		errs := r.runAction(check, serverCfg, result.Return, counterCopy)
+		r.setLastAction(serverCfg.Server.Name, checkID, check.Limit.Actions, errs)
 	} else {
+		r.Unlock()
 		log.WithField("server", result.Server.Server.Name).Debugf("nothing to do for server %s", serverCfg.Server.Name)
 	}
 }
 
-func (r *ResultServerList) runAction(check config.Check, serverCfg *config.Config) {
-	wg := sync.WaitGroup{}
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		for _, action := range check.Limit.Actions {
-			switch strings.ToLower(action) {
-			case "log":
-				log.WithField("server", serverCfg.Server.Name).Warn("runAction dummy log action")
-			case "restart":
-				r.restartAction(check, serverCfg)
-			}
+// runAction run the actions of the check limit one after another, errors are
+// recorded per action in the check status. Each decision is appended to the
+// journal together with the triggering result, counter and limit.
+func (r *ResultServerList) runAction(check config.Check, serverCfg *config.Config, result checks.Result, counter ResultCounter) map[string]string {
+	logger := log.WithFields(log.Fields{
+		"server": serverCfg.Server.Name,
+		"check":  check.GetID(),
+	})
+
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	dryRun := viper.GetBool("dry-run")
+	event := actions.Event{
+		Server:     serverCfg,
+		CheckID:    check.GetID(),
+		Result:     result,
+		Time:       time.Now(),
+		Controller: r.controller,
+	}
+	newEntry := func(decision string, action string) JournalEntry {
+		return JournalEntry{
+			Server:   serverCfg.Server.Name,
+			Source:   SourceChecker,
+			Check:    check.GetID(),
+			Decision: decision,
+			Action:   action,
+			DryRun:   dryRun,
+			Result:   &result,
+			Counter:  &counter,
+			Limit:    check.Limit,
 		}
-	}()
+	}
+
+	errs := map[string]string{}
+	if q := r.Quarantined(serverCfg.Server.Name); q != nil {
+		logger.Warnf("server %s is quarantined, not running actions. %s", serverCfg.Server.Name, q.Reason)
+		for _, name := range check.Limit.Actions {
+			errs[strings.ToLower(name)] = "server is quarantined"
+		}
+		r.journal.Append(newEntry(DecisionQuarantined, ""))
+		return errs
+	}
 
-	wg.Wait()
-	return
+	for _, name := range check.Limit.Actions {
+		name = strings.ToLower(name)
+		actionsTotal.WithLabelValues(serverCfg.Server.Name, name).Inc()
+
+		if dryRun && name != "log" {
+			logger.Infof("dry-run mode active, not running action %s for server %s", name, serverCfg.Server.Name)
+			r.journal.Append(newEntry(DecisionAction, name))
+			continue
+		}
+
+		if name == "restart" || name == "nicerestart" {
+			r.queueRestart(ctx, name, check, serverCfg, event, newEntry)
+			continue
+		}
+
+		if err := r.execAction(ctx, name, check, serverCfg, event, newEntry); err != nil {
+			errs[name] = err.Error()
+		}
+	}
+
+	return errs
 }
 
-func (r *ResultServerList) restartAction(check config.Check, serverCfg *config.Config) {
-	if viper.GetBool("dry-run") {
-		log.WithField("server", serverCfg.Server.Name).Infof("dry-run mode active, server %s restart", serverCfg.Server.Name)
-	} else {
-		log.WithField("server", serverCfg.Server.Name).Infof("need to restart server %s", serverCfg.Server.Name)
-		if err := server.SendCommand(serverCfg, []string{"say", "SRCDS CHECKER RESTART MARKER"}); err != nil {
-			log.Error(err)
+// queueRestart queue the restart action of the server, the restart budget is
+// applied when the restart is run from the queue
+func (r *ResultServerList) queueRestart(ctx context.Context, name string, check config.Check, serverCfg *config.Config, event actions.Event, newEntry func(decision string, action string) JournalEntry) {
+	logger := log.WithFields(log.Fields{
+		"server": serverCfg.Server.Name,
+		"check":  check.GetID(),
+	})
+
+	item := &QueuedAction{
+		Server: serverCfg.Server.Name,
+		Action: name,
+		Source: SourceChecker,
+		Reason: fmt.Sprintf("check %s", check.GetID()),
+	}
+	queued := restartQueue.Enqueue(item, func() {
+		if !r.allowRestart(serverCfg, item.Reason) {
+			entry := newEntry(DecisionRestartDenied, name)
+			entry.Error = "restart budget used up, server is quarantined"
+			r.journal.Append(entry)
+			r.setActionError(serverCfg.Server.Name, check.GetID(), name, entry.Error)
+			return
 		}
+		r.openIncident(serverCfg, fmt.Sprintf("The server is being restarted, check %s failed: %s", check.GetID(), event.Result.Message), statuspage.IncidentIdentified, statuspage.ComponentPartialOutage)
 
-		if err := server.Restart(serverCfg); err != nil {
-			log.WithField("server", serverCfg.Server.Name).Error(err)
+		if err := r.execAction(ctx, name, check, serverCfg, event, newEntry); err != nil {
+			r.setActionError(serverCfg.Server.Name, check.GetID(), name, err.Error())
+			return
 		}
-		log.WithField("server", serverCfg.Server.Name).Infof("server %s restarted", serverCfg.Server.Name)
+		restartsTotal.WithLabelValues(serverCfg.Server.Name, "checker").Inc()
+	})
+	if !queued {
+		logger.Infof("action %s for server %s not queued, an action is already queued or running for it", name, serverCfg.Server.Name)
+		r.journal.Append(newEntry(DecisionDeduplicated, name))
+		return
+	}
+	logger.Infof("queued action %s for server %s", name, serverCfg.Server.Name)
+	r.journal.Append(newEntry(DecisionQueued, name))
+}
+
+// execAction run the action, journal and notify about it
+func (r *ResultServerList) execAction(ctx context.Context, name string, check config.Check, serverCfg *config.Config, event actions.Event, newEntry func(decision string, action string) JournalEntry) error {
+	logger := log.WithFields(log.Fields{
+		"server": serverCfg.Server.Name,
+		"check":  check.GetID(),
+	})
+	result := event.Result
+
+	entry := newEntry(DecisionAction, name)
+	action, err := actions.New(name, check.Limit.ActionOpts)
+	if err == nil {
+		logger.Infof("running action %s for server %s", name, serverCfg.Server.Name)
+		entry.Executed = true
+		err = action.Run(ctx, event)
+	}
+	notifyEvent := notify.Event{
+		Type:    notify.EventCheckerAction,
+		Message: fmt.Sprintf("action %s run for check %s (%s: %s)", name, check.GetID(), result.Status, result.Message),
+		Attributes: map[string]string{
+			"action": name,
+			"check":  check.GetID(),
+		},
+	}
+	if err != nil {
+		logger.Errorf("action %s failed for server %s. %+v", name, serverCfg.Server.Name, err)
+		actionErrorsTotal.WithLabelValues(serverCfg.Server.Name, name).Inc()
+		entry.Error = err.Error()
+		r.journal.Append(entry)
+		notifyEvent.Message = fmt.Sprintf("action %s failed for check %s (%s: %s). %s", name, check.GetID(), result.Status, result.Message, err)
+		notify.SendAsync(serverCfg, notifyEvent)
+		return err
 	}
+	r.journal.Append(entry)
+	notify.SendAsync(serverCfg, notifyEvent)
+	return nil
 }
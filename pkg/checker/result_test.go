@@ -17,8 +17,11 @@ limitations under the License.
 package checker
 
 import (
+	"fmt"
 	"testing"
 
+	_ "github.com/galexrt/srcds_controller/pkg/actions/log"
+	"github.com/galexrt/srcds_controller/pkg/checks"
 	"github.com/galexrt/srcds_controller/pkg/config"
 	log "github.com/sirupsen/logrus"
 )
@@ -41,6 +44,7 @@ func TestAdd(t *testing.T) {
 				},
 			},
 		},
+		Return: checks.Critical("dummy"),
 	}
 	result2 := Result{
 		Server: &config.Config{
@@ -57,6 +61,7 @@ func TestAdd(t *testing.T) {
 				},
 			},
 		},
+		Return: checks.Critical("dummy"),
 	}
 	r.Add(result1)
 	r.Add(result1)
@@ -67,3 +72,77 @@ func TestAdd(t *testing.T) {
 	r.Add(result2)
 	r.Add(result2)
 }
+
+func TestAddStatus(t *testing.T) {
+	r := NewResultServerList()
+	result := Result{
+		Server: &config.Config{
+			Server: &config.Server{
+				Name: "server1",
+			},
+		},
+		Check: config.Check{
+			Name: "dummy",
+			Limit: &config.Limit{
+				Count: 10,
+			},
+		},
+	}
+
+	result.Return = checks.Critical("failed")
+	r.Add(result)
+	r.Add(result)
+	if count := r.results["server1"]["dummy"].Count; count != 2 {
+		t.Fatalf("expected count 2 after two critical results, got %d", count)
+	}
+
+	result.Return = checks.Unknown(fmt.Errorf("check errored"))
+	r.Add(result)
+	if count := r.results["server1"]["dummy"].Count; count != 2 {
+		t.Fatalf("expected unknown result not to be counted, got %d", count)
+	}
+
+	result.Return = checks.OK("healthy")
+	r.Add(result)
+	if _, ok := r.results["server1"]["dummy"]; ok {
+		t.Fatal("expected ok result to reset the result counter")
+	}
+}
+
+func TestAddGracePeriod(t *testing.T) {
+	r := NewResultServerList()
+	result := Result{
+		Server: &config.Config{
+			Server: &config.Server{
+				Name: "server1",
+			},
+		},
+		Check: config.Check{
+			Name: "dummy",
+			Limit: &config.Limit{
+				Count: 2,
+			},
+		},
+		Return:      checks.Critical("failed"),
+		GracePeriod: true,
+	}
+
+	r.Add(result)
+	r.Add(result)
+	r.Add(result)
+	counter := r.results["server1"]["dummy"]
+	if counter.Count != 0 || counter.GraceCount != 3 {
+		t.Fatalf("expected failures to be recorded but not counted during grace period, got count %d grace count %d", counter.Count, counter.GraceCount)
+	}
+
+	// The limit starts counting at the end of the grace period
+	result.GracePeriod = false
+	r.Add(result)
+	if count := r.results["server1"]["dummy"].Count; count != 1 {
+		t.Fatalf("expected first failure after grace period to be counted, got count %d", count)
+	}
+	r.Add(result)
+	if count := r.results["server1"]["dummy"].Count; count != 0 {
+		t.Fatalf("expected counter reset after action outside of grace period, got count %d", count)
+	}
+}
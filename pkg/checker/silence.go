@@ -0,0 +1,112 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Silence maintenance silence of a server, no actions are run by the checker
+// and no Docker events are reacted to until the silence expires
+type Silence struct {
+	Server    string    `json:"server"`
+	Reason    string    `json:"reason"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+}
+
+// Active if the silence is active at the given time
+func (s *Silence) Active(now time.Time) bool {
+	return !now.Before(s.Start) && now.Before(s.End)
+}
+
+// AddSilence add (or replace) the silence of the server
+func (r *ResultServerList) AddSilence(silence *Silence) {
+	r.Lock()
+	r.silences[silence.Server] = silence
+	r.Unlock()
+	log.WithField("server", silence.Server).Infof("server silenced until %s (%s)", silence.End.Format(time.RFC3339), silence.Reason)
+	// Don't lose the silence when the checker is restarted soon after
+	r.saveState()
+}
+
+// RemoveSilence remove the silence of the server, returns false when the
+// server isn't silenced
+func (r *ResultServerList) RemoveSilence(serverName string) bool {
+	r.Lock()
+	if _, ok := r.silences[serverName]; !ok {
+		r.Unlock()
+		return false
+	}
+	delete(r.silences, serverName)
+	r.Unlock()
+	log.WithField("server", serverName).Info("server silence removed")
+	r.saveState()
+	return true
+}
+
+// Silences return the active silences sorted by server name, expired
+// silences are removed
+func (r *ResultServerList) Silences() []*Silence {
+	r.Lock()
+	defer r.Unlock()
+
+	now := time.Now()
+	silences := []*Silence{}
+	for name, silence := range r.silences {
+		if !now.Before(silence.End) {
+			delete(r.silences, name)
+			continue
+		}
+		silenceCopy := *silence
+		silences = append(silences, &silenceCopy)
+	}
+	sort.Slice(silences, func(i, j int) bool {
+		return silences[i].Server < silences[j].Server
+	})
+	return silences
+}
+
+// silenced return the reason if the server is silenced either by a silence or
+// one of the maintenance windows of its config
+func (r *ResultServerList) silenced(serverCfg *config.Config) (string, bool) {
+	r.RLock()
+	silence := r.silences[serverCfg.Server.Name]
+	r.RUnlock()
+	return silenceReason(serverCfg, silence, time.Now())
+}
+
+func silenceReason(serverCfg *config.Config, silence *Silence, now time.Time) (string, bool) {
+	if silence != nil && silence.Active(now) {
+		return fmt.Sprintf("silenced until %s: %s", silence.End.Format(time.RFC3339), silence.Reason), true
+	}
+
+	if serverCfg.Checker != nil {
+		for _, window := range serverCfg.Checker.MaintenanceWindows {
+			if window.Active(now) {
+				return "maintenance window: " + window.Reason, true
+			}
+		}
+	}
+	return "", false
+}
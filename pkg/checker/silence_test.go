@@ -0,0 +1,131 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+func TestSilenced(t *testing.T) {
+	r := NewResultServerList()
+	serverCfg := &config.Config{
+		Server: &config.Server{
+			Name: "server1",
+		},
+		Checker: &config.Checker{},
+	}
+
+	if _, ok := r.silenced(serverCfg); ok {
+		t.Fatal("expected server1 not to be silenced")
+	}
+
+	r.AddSilence(&Silence{
+		Server: "server1",
+		Reason: "update",
+		Start:  time.Now().Add(-time.Minute),
+		End:    time.Now().Add(time.Hour),
+	})
+	if _, ok := r.silenced(serverCfg); !ok {
+		t.Fatal("expected server1 to be silenced")
+	}
+	if !r.RemoveSilence("server1") {
+		t.Fatal("expected silence of server1 to be removed")
+	}
+
+	r.AddSilence(&Silence{
+		Server: "server1",
+		Start:  time.Now().Add(-2 * time.Hour),
+		End:    time.Now().Add(-time.Hour),
+	})
+	if _, ok := r.silenced(serverCfg); ok {
+		t.Fatal("expected expired silence to be ignored")
+	}
+	if silences := r.Silences(); len(silences) != 0 {
+		t.Fatalf("expected expired silence to be removed, got %d silences", len(silences))
+	}
+}
+
+func TestMaintenanceWindow(t *testing.T) {
+	// Saturday 2021-01-02
+	loc := time.Local
+	tests := []struct {
+		window *config.MaintenanceWindow
+		now    time.Time
+		active bool
+	}{
+		{&config.MaintenanceWindow{Start: "04:00", Duration: time.Hour}, time.Date(2021, 1, 2, 4, 30, 0, 0, loc), true},
+		{&config.MaintenanceWindow{Start: "04:00", Duration: time.Hour}, time.Date(2021, 1, 2, 5, 0, 0, 0, loc), false},
+		{&config.MaintenanceWindow{Start: "04:00", Duration: time.Hour, Weekdays: []string{"sat"}}, time.Date(2021, 1, 2, 4, 30, 0, 0, loc), true},
+		{&config.MaintenanceWindow{Start: "04:00", Duration: time.Hour, Weekdays: []string{"sun"}}, time.Date(2021, 1, 2, 4, 30, 0, 0, loc), false},
+		// Window crossing midnight started on friday
+		{&config.MaintenanceWindow{Start: "23:00", Duration: 2 * time.Hour, Weekdays: []string{"fri"}}, time.Date(2021, 1, 2, 0, 30, 0, 0, loc), true},
+	}
+
+	for i, test := range tests {
+		serverCfg := &config.Config{
+			Server: &config.Server{
+				Name: "server1",
+			},
+			Checker: &config.Checker{
+				MaintenanceWindows: []*config.MaintenanceWindow{test.window},
+			},
+		}
+		if _, active := silenceReason(serverCfg, nil, test.now); active != test.active {
+			t.Errorf("test %d: expected maintenance window %+v active %t at %s", i, test.window, test.active, test.now)
+		}
+	}
+}
+
+func TestSilenceSavedToState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "srcds_controller_state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := NewResultServerList()
+	r.stateFile = filepath.Join(dir, "state.json")
+	for _, serverName := range []string{"server1", "server2"} {
+		r.AddSilence(&Silence{
+			Server: serverName,
+			Start:  time.Now(),
+			End:    time.Now().Add(time.Hour),
+		})
+	}
+
+	restored := NewResultServerList()
+	if err := restored.Load(r.stateFile); err != nil {
+		t.Fatal(err)
+	}
+	restored.Prune(map[string]*config.Config{
+		"server1": {
+			Server: &config.Server{
+				Name:    "server1",
+				Enabled: true,
+			},
+		},
+	})
+	if silences := restored.Silences(); len(silences) != 1 || silences[0].Server != "server1" {
+		t.Fatalf("expected only the silence of server1 to be restored, got %+v", silences)
+	}
+}
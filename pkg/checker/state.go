@@ -0,0 +1,234 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// stateVersion version of the checker state file format
+const stateVersion = 1
+
+// state checker state which is persisted to the state file
+type state struct {
+	Version int                                  `json:"version"`
+	SavedAt time.Time                            `json:"savedAt"`
+	Results map[string]map[string]*ResultCounter `json:"results"`
+	// Restarts and Quarantine of the restart budgets
+	Restarts   map[string][]time.Time `json:"restarts,omitempty"`
+	Quarantine map[string]*Quarantine `json:"quarantine,omitempty"`
+	Silences   map[string]*Silence    `json:"silences,omitempty"`
+	// Incidents status page incidents opened by the controller
+	Incidents map[string][]int `json:"incidents,omitempty"`
+}
+
+// Save snapshot the result counters to the given state file
+func (r *ResultServerList) Save(path string) error {
+	r.RLock()
+	out, err := json.MarshalIndent(state{
+		Version:    stateVersion,
+		SavedAt:    time.Now(),
+		Results:    r.results,
+		Restarts:   r.restarts,
+		Quarantine: r.quarantine,
+		Silences:   r.silences,
+		Incidents:  r.incidents,
+	}, "", "  ")
+	r.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so the state file is replaced atomically
+	tmpFile, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(out); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), path)
+}
+
+// saveState save the state to the state file right away, when one is set
+func (r *ResultServerList) saveState() {
+	r.RLock()
+	path := r.stateFile
+	r.RUnlock()
+	if path == "" {
+		return
+	}
+	if err := r.Save(path); err != nil {
+		log.Errorf("failed to save checker state file %s. %+v", path, err)
+	}
+}
+
+// Load restore the result counters from the given state file, a non existing
+// state file is not an error
+func (r *ResultServerList) Load(path string) error {
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	st := state{}
+	if err := json.Unmarshal(out, &st); err != nil {
+		return err
+	}
+	if st.Version != stateVersion {
+		log.Warnf("ignoring checker state file %s with unknown version %d", path, st.Version)
+		return nil
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	for serverName, counters := range st.Results {
+		for checkID, counter := range counters {
+			if counter == nil {
+				continue
+			}
+			if _, ok := r.results[serverName]; !ok {
+				r.results[serverName] = map[string]*ResultCounter{}
+			}
+			r.results[serverName][checkID] = counter
+		}
+	}
+	for serverName, restarts := range st.Restarts {
+		r.restarts[serverName] = restarts
+	}
+	for serverName, q := range st.Quarantine {
+		if q == nil {
+			continue
+		}
+		r.quarantine[serverName] = q
+	}
+	now := time.Now()
+	for serverName, silence := range st.Silences {
+		if silence == nil || !now.Before(silence.End) {
+			continue
+		}
+		r.silences[serverName] = silence
+	}
+	for serverName, ids := range st.Incidents {
+		r.incidents[serverName] = ids
+	}
+
+	log.Infof("restored checker state from %s (saved at %s)", path, st.SavedAt)
+	return nil
+}
+
+// Prune remove the result counters of servers and checks which don't exist
+// (anymore) in the given server configs or whose server is disabled
+func (r *ResultServerList) Prune(servers map[string]*config.Config) {
+	r.Lock()
+	defer r.Unlock()
+
+	for serverName, statuses := range r.status {
+		serverCfg, ok := servers[serverName]
+		if !ok || !serverCfg.Server.Enabled {
+			delete(r.status, serverName)
+			continue
+		}
+		checkIDs := map[string]bool{}
+		for _, check := range serverCfg.Server.Checks {
+			checkIDs[check.GetID()] = true
+		}
+		for checkID := range statuses {
+			if !checkIDs[checkID] {
+				delete(statuses, checkID)
+			}
+		}
+	}
+
+	for serverName := range r.quarantine {
+		if serverCfg, ok := servers[serverName]; !ok || !serverCfg.Server.Enabled {
+			delete(r.quarantine, serverName)
+		}
+	}
+	for serverName := range r.restarts {
+		if serverCfg, ok := servers[serverName]; !ok || !serverCfg.Server.Enabled {
+			delete(r.restarts, serverName)
+		}
+	}
+	for serverName := range r.silences {
+		if _, ok := servers[serverName]; !ok {
+			delete(r.silences, serverName)
+		}
+	}
+	for serverName := range r.incidents {
+		if _, ok := servers[serverName]; !ok {
+			delete(r.incidents, serverName)
+		}
+	}
+
+	for serverName, counters := range r.results {
+		serverCfg, ok := servers[serverName]
+		if !ok || !serverCfg.Server.Enabled {
+			log.WithField("server", serverName).Debug("pruning result counters of removed / disabled server")
+			delete(r.results, serverName)
+			continue
+		}
+
+		checkIDs := map[string]bool{}
+		for _, check := range serverCfg.Server.Checks {
+			checkIDs[check.GetID()] = true
+		}
+		for checkID := range counters {
+			if !checkIDs[checkID] {
+				log.WithField("server", serverName).Debugf("pruning result counter of removed check %s", checkID)
+				delete(counters, checkID)
+			}
+		}
+		if len(counters) == 0 {
+			delete(r.results, serverName)
+		}
+	}
+}
+
+// Reset remove the result counter of a server check
+func (r *ResultServerList) Reset(serverName string, checkID string) {
+	r.Lock()
+	defer r.Unlock()
+	if counters, ok := r.results[serverName]; ok {
+		delete(counters, checkID)
+	}
+}
+
+// RemoveServer remove the result counters and check status of a removed server
+func (r *ResultServerList) RemoveServer(serverName string) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.results, serverName)
+	delete(r.status, serverName)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+func TestSaveLoadPrune(t *testing.T) {
+	dir, err := ioutil.TempDir("", "srcds_controller_state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "state.json")
+
+	firstTime := time.Now().Add(-5 * time.Minute).Round(time.Second)
+	r := NewResultServerList()
+	r.results = map[string]map[string]*ResultCounter{
+		"server1": {
+			"rcon":    {Count: 2, FirstTime: firstTime, LastTime: firstTime},
+			"removed": {Count: 1},
+		},
+		"server2": {
+			"rcon": {Count: 1},
+		},
+	}
+	if err := r.Save(stateFile); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewResultServerList()
+	if err := restored.Load(stateFile); err != nil {
+		t.Fatal(err)
+	}
+	restored.Prune(map[string]*config.Config{
+		"server1": {
+			Server: &config.Server{
+				Name:    "server1",
+				Enabled: true,
+				Checks: []config.Check{
+					{Name: "rcon"},
+				},
+			},
+		},
+	})
+
+	if _, ok := restored.results["server2"]; ok {
+		t.Error("expected counters of removed server2 to be pruned")
+	}
+	if _, ok := restored.results["server1"]["removed"]; ok {
+		t.Error("expected counter of removed check to be pruned")
+	}
+	counter, ok := restored.results["server1"]["rcon"]
+	if !ok {
+		t.Fatal("expected rcon counter of server1 to be restored")
+	}
+	if counter.Count != 2 || !counter.FirstTime.Equal(firstTime) {
+		t.Errorf("unexpected restored counter %+v", counter)
+	}
+}
+
+func TestLoadMissingStateFile(t *testing.T) {
+	if err := NewResultServerList().Load("/nonexistent/state.json"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIncidentsState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "srcds_controller_state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "state.json")
+
+	r := NewResultServerList()
+	r.TrackIncident("server1", 3)
+	r.TrackIncident("server1", 3)
+	r.TrackIncident("server2", 4)
+	if err := r.Save(stateFile); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewResultServerList()
+	if err := restored.Load(stateFile); err != nil {
+		t.Fatal(err)
+	}
+	restored.Prune(map[string]*config.Config{
+		"server1": {
+			Server: &config.Server{
+				Name:    "server1",
+				Enabled: true,
+			},
+		},
+	})
+	if ids := restored.incidents["server1"]; len(ids) != 1 || ids[0] != 3 {
+		t.Errorf("expected incident 3 of server1 to be restored, got %v", ids)
+	}
+	if _, ok := restored.incidents["server2"]; ok {
+		t.Error("expected incidents of removed server2 to be pruned")
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"sort"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/checks"
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+// ServerStatus checker status of a server
+type ServerStatus struct {
+	Name    string         `json:"name"`
+	NextRun time.Time      `json:"nextRun"`
+	Checks  []*CheckStatus `json:"checks"`
+	// Disabled reason why the server has been disabled by an action
+	Disabled string `json:"disabled,omitempty"`
+	// Quarantine set when the server has used up its restart budget
+	Quarantine *Quarantine `json:"quarantine,omitempty"`
+	// Silenced reason when the server is silenced or in a maintenance window
+	Silenced string `json:"silenced,omitempty"`
+	// Queued restart of the server which is queued or running
+	Queued *QueuedAction `json:"queued,omitempty"`
+}
+
+// CheckStatus checker status of a server check
+type CheckStatus struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Limit       *config.Limit  `json:"limit,omitempty"`
+	LastResult  *checks.Result `json:"lastResult,omitempty"`
+	LastRun     time.Time      `json:"lastRun"`
+	NextRun     time.Time      `json:"nextRun"`
+	GracePeriod bool           `json:"gracePeriod"`
+	Counter     *ResultCounter `json:"counter,omitempty"`
+	LastAction  *ActionStatus  `json:"lastAction,omitempty"`
+}
+
+// ActionStatus actions which have been run for a server check
+type ActionStatus struct {
+	Actions []string  `json:"actions"`
+	Time    time.Time `json:"time"`
+	// Errors error message by action name of the actions which failed
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+func (r *ResultServerList) getStatus(serverName string, checkID string) *CheckStatus {
+	if _, ok := r.status[serverName]; !ok {
+		r.status[serverName] = map[string]*CheckStatus{}
+	}
+	if _, ok := r.status[serverName][checkID]; !ok {
+		r.status[serverName][checkID] = &CheckStatus{}
+	}
+	return r.status[serverName][checkID]
+}
+
+func (r *ResultServerList) setLastResult(result Result) {
+	r.Lock()
+	defer r.Unlock()
+
+	lastResult := result.Return
+	status := r.getStatus(result.Server.Server.Name, result.Check.GetID())
+	status.LastResult = &lastResult
+	status.LastRun = time.Now()
+	status.GracePeriod = result.GracePeriod
+}
+
+func (r *ResultServerList) setLastAction(serverName string, checkID string, actions []string, errs map[string]string) {
+	r.Lock()
+	defer r.Unlock()
+
+	status := r.getStatus(serverName, checkID)
+	status.LastAction = &ActionStatus{
+		Actions: actions,
+		Time:    time.Now(),
+		Errors:  errs,
+	}
+}
+
+// setActionError add the error of an action run after the last action status
+// has been set, e.g., a queued restart
+func (r *ResultServerList) setActionError(serverName string, checkID string, action string, err string) {
+	r.Lock()
+	defer r.Unlock()
+
+	status := r.getStatus(serverName, checkID)
+	if status.LastAction == nil {
+		status.LastAction = &ActionStatus{
+			Actions: []string{action},
+			Time:    time.Now(),
+		}
+	}
+	if status.LastAction.Errors == nil {
+		status.LastAction.Errors = map[string]string{}
+	}
+	status.LastAction.Errors[action] = err
+}
+
+// Status return the checker status of all running servers sorted by name
+func (c *Checker) Status() []*ServerStatus {
+	c.Lock()
+	defer c.Unlock()
+
+	resultCounter.RLock()
+	defer resultCounter.RUnlock()
+
+	queued := map[string]QueuedAction{}
+	for _, item := range restartQueue.Queue() {
+		queued[item.Server] = item
+	}
+
+	statuses := []*ServerStatus{}
+	for name, running := range c.servers {
+		srvStatus := &ServerStatus{
+			Name:    name,
+			NextRun: running.checks.getNextRun(),
+			Checks:  []*CheckStatus{},
+		}
+		if q, ok := resultCounter.quarantine[name]; ok {
+			qCopy := *q
+			srvStatus.Quarantine = &qCopy
+		}
+		srvStatus.Silenced, _ = silenceReason(running.checks.server, resultCounter.silences[name], time.Now())
+		if item, ok := queued[name]; ok {
+			srvStatus.Queued = &item
+		}
+		for _, check := range running.checks.server.Server.Checks {
+			checkStatus := &CheckStatus{}
+			if status, ok := resultCounter.status[name][check.GetID()]; ok {
+				*checkStatus = *status
+			}
+			checkStatus.ID = check.GetID()
+			checkStatus.Name = check.Name
+			checkStatus.Limit = check.Limit
+			checkStatus.NextRun = running.checks.getCheckNextRun(check.GetID())
+			if counter, ok := resultCounter.results[name][check.GetID()]; ok {
+				counterCopy := *counter
+				checkStatus.Counter = &counterCopy
+			}
+			srvStatus.Checks = append(srvStatus.Checks, checkStatus)
+		}
+		statuses = append(statuses, srvStatus)
+	}
+	for name, reason := range c.disabled {
+		statuses = append(statuses, &ServerStatus{
+			Name:     name,
+			Checks:   []*CheckStatus{},
+			Disabled: reason,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/statuspage"
+)
+
+// openIncident open a status page incident for the server, or update the one
+// opened before when still open. Only the incidents opened by the controller
+// are resolved once all checks of the server are healthy again.
+func (r *ResultServerList) openIncident(serverCfg *config.Config, message string, status statuspage.IncidentStatus, componentStatus statuspage.ComponentStatus) {
+	r.RLock()
+	ids := append([]int{}, r.incidents[serverCfg.Server.Name]...)
+	r.RUnlock()
+
+	if id := statuspage.OpenIncident(serverCfg, message, status, componentStatus, ids); id != 0 {
+		r.TrackIncident(serverCfg.Server.Name, id)
+	}
+}
+
+// TrackIncident record an incident opened by the controller (e.g., by `sc`)
+// for the server, it is resolved once the server is healthy again
+func (r *ResultServerList) TrackIncident(serverName string, id int) {
+	r.Lock()
+	defer r.Unlock()
+	for _, i := range r.incidents[serverName] {
+		if i == id {
+			return
+		}
+	}
+	r.incidents[serverName] = append(r.incidents[serverName], id)
+}
+
+// observeIncidents resolve the recorded incidents of the server when all
+// results of the tick are healthy
+func (r *ResultServerList) observeIncidents(serverCfg *config.Config, results []Result) {
+	if !serverCfg.StatusPage.Enabled() || len(results) == 0 {
+		return
+	}
+
+	for _, result := range results {
+		if !result.Return.Healthy() {
+			return
+		}
+	}
+
+	r.Lock()
+	ids := r.incidents[serverCfg.Server.Name]
+	delete(r.incidents, serverCfg.Server.Name)
+	r.Unlock()
+
+	if len(ids) > 0 {
+		statuspage.ResolveIncidents(serverCfg, "The server is healthy again.", ids)
+	}
+}
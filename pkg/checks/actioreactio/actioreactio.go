@@ -17,17 +17,14 @@ limitations under the License.
 package actioreactio
 
 import (
-	"bufio"
 	"context"
-	"io"
+	"fmt"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/galexrt/srcds_controller/pkg/checks"
 	"github.com/galexrt/srcds_controller/pkg/config"
 	"github.com/galexrt/srcds_controller/pkg/server"
-	"github.com/imdario/mergo"
 	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
 )
@@ -39,87 +36,86 @@ var (
 )
 
 func init() {
-	checks.Checks["actioreactio"] = Run
+	checks.Register("actioreactio", New)
 }
 
-// Run run a actioreactio check on a config.Server
-func Run(check config.Check, srv *config.Config) bool {
-	logger := log.WithFields(logrus.Fields{
-		"server": srv.Server.Name,
-	})
+// Check actioreactio check
+type Check struct {
+	timeout time.Duration
+}
 
-	if err := mergo.Map(&check.Opts, defaultOpts); err != nil {
-		logger.Fatalf("failed to merge checks opts and actioreactio check defaults %s", srv.Server.Name)
+// New return a new actioreactio check from the given opts
+func New(opts config.CheckOpts) (checks.Check, error) {
+	opts, err := checks.MergeDefaults(opts, defaultOpts)
+	if err != nil {
+		return nil, err
 	}
 
-	timeoutDuration, err := time.ParseDuration(check.Opts["timeout"])
+	timeout, err := time.ParseDuration(opts["timeout"])
 	if err != nil {
-		logger.Errorf("failed to parse actioreactio timeout check opts. %+v", err)
-		return false
+		return nil, fmt.Errorf("failed to parse actioreactio timeout opt. %w", err)
 	}
 
+	return &Check{
+		timeout: timeout,
+	}, nil
+}
+
+// Run run a actioreactio check on a config.Server
+func (c *Check) Run(ctx context.Context, srv *config.Config) checks.Result {
+	logger := log.WithFields(logrus.Fields{
+		"server": srv.Server.Name,
+	})
+
 	startTime := time.Now()
-	ctx, cancel := context.WithTimeout(context.Background(), timeoutDuration)
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
-	cmd, stdout, stderr, err := server.Logs(ctx, srv, 0*time.Second, 5, true)
+	lines, err := server.LogStream(ctx, srv, server.LogOptions{
+		Tail:   5,
+		Follow: true,
+	})
 	if err != nil {
-		logger.Errorf("error while getting logs from server. %+v", err)
-		return false
+		return checks.Unknown(fmt.Errorf("error while getting logs from server. %w", err))
 	}
-	defer stdout.Close()
-
-	wg := &sync.WaitGroup{}
 
 	foundCh := make(chan bool, 1)
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		cmd.Wait()
-	}()
-	wg.Add(1)
 	go func() {
-		defer wg.Done()
-		checkStreamForString(stderr, foundCh, `Unknown command "srcds_controller_check"`)
+		foundCh <- waitForLine(lines, startTime, server.StreamStderr, `Unknown command "srcds_controller_check"`)
 	}()
 
 	if err := server.SendCommand(srv, []string{
 		"srcds_controller_check",
 	}); err != nil {
-		logger.Errorf("error while sending actioreactio command to server. %+v", err)
-		return false
+		return checks.Critical("error while sending actioreactio command to server. %+v", err)
 	}
 
-	result := false
+	found := false
 	select {
 	case <-ctx.Done():
-		logger.Errorf("timeout while waiting for actioreactio output (%+v)", time.Now().Sub(startTime))
-	case result = <-foundCh:
-		logger.Debugf("got a result in time (%+v): %+v", time.Now().Sub(startTime), result)
+		logger.Errorf("timeout while waiting for actioreactio output (%+v)", time.Since(startTime))
+	case found = <-foundCh:
+		logger.Debugf("got a result in time (%+v): %+v", time.Since(startTime), found)
 	}
 
-	wg.Wait()
-	close(foundCh)
-	return result
+	if !found {
+		return checks.Critical("no actioreactio output received from server within %s", c.timeout)
+	}
+	return checks.OK("actioreactio output received from server")
 }
 
-func checkStreamForString(stream io.ReadCloser, foundCh chan bool, search string) {
-	defer stream.Close()
-
-	scanner := bufio.NewScanner(stream)
-	for scanner.Scan() {
-		text := scanner.Text()
-		log.Debugf("checkStreamForString line: %+v", text)
-		if strings.Contains(text, search) {
-			foundCh <- true
-			return
+// waitForLine wait for a line of the given stream logged after the given time
+// containing the search string, returns false when the lines channel is
+// closed before
+func waitForLine(lines <-chan server.LogLine, after time.Time, stream string, search string) bool {
+	for line := range lines {
+		log.Debugf("waitForLine line: %+v", line.Text)
+		if !line.Timestamp.IsZero() && line.Timestamp.Before(after) {
+			continue
+		}
+		if line.Stream == stream && strings.Contains(line.Text, search) {
+			return true
 		}
 	}
-
-	if err := scanner.Err(); err != nil {
-		log.Debugf("error during logs line scanning. %+v", err)
-	}
-
-	foundCh <- false
+	return false
 }
@@ -0,0 +1,148 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+// Check a check which can be run against a server
+type Check interface {
+	// Run run the check against the given server. The context is cancelled
+	// when the checker is shutting down.
+	Run(ctx context.Context, srv *config.Config) Result
+}
+
+// Status status of a check result
+type Status int
+
+const (
+	// StatusOK server is healthy
+	StatusOK Status = iota
+	// StatusWarning server is degraded but doesn't count as failed
+	StatusWarning
+	// StatusCritical server is unhealthy, counts towards the check limits
+	StatusCritical
+	// StatusUnknown the check itself errored, doesn't count towards the check limits
+	StatusUnknown
+	// StatusSkipped the check wasn't run by the checker because one of its
+	// dependencies isn't healthy
+	StatusSkipped
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusOK:
+		return "OK"
+	case StatusWarning:
+		return "WARNING"
+	case StatusCritical:
+		return "CRITICAL"
+	case StatusUnknown:
+		return "UNKNOWN"
+	case StatusSkipped:
+		return "SKIPPED"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *Status) UnmarshalText(text []byte) error {
+	switch strings.ToUpper(string(text)) {
+	case "OK":
+		*s = StatusOK
+	case "WARNING":
+		*s = StatusWarning
+	case "CRITICAL":
+		*s = StatusCritical
+	case "UNKNOWN":
+		*s = StatusUnknown
+	case "SKIPPED":
+		*s = StatusSkipped
+	default:
+		return fmt.Errorf("unknown check status %q", string(text))
+	}
+	return nil
+}
+
+// Result result of a check run
+type Result struct {
+	Status     Status            `json:"status"`
+	Message    string            `json:"message,omitempty"`
+	Latency    time.Duration     `json:"latency"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Failed if the result counts as a failure towards the check limits
+func (r Result) Failed() bool {
+	return r.Status == StatusCritical
+}
+
+// Healthy if the result is either OK or a warning
+func (r Result) Healthy() bool {
+	return r.Status == StatusOK || r.Status == StatusWarning
+}
+
+// OK return a StatusOK result with the given message
+func OK(msg string, args ...interface{}) Result {
+	return Result{
+		Status:  StatusOK,
+		Message: fmt.Sprintf(msg, args...),
+	}
+}
+
+// Warning return a StatusWarning result with the given message
+func Warning(msg string, args ...interface{}) Result {
+	return Result{
+		Status:  StatusWarning,
+		Message: fmt.Sprintf(msg, args...),
+	}
+}
+
+// Critical return a StatusCritical result with the given message
+func Critical(msg string, args ...interface{}) Result {
+	return Result{
+		Status:  StatusCritical,
+		Message: fmt.Sprintf(msg, args...),
+	}
+}
+
+// Unknown return a StatusUnknown result for the given error
+func Unknown(err error) Result {
+	return Result{
+		Status:  StatusUnknown,
+		Message: err.Error(),
+	}
+}
+
+// Skipped return a StatusSkipped result with the given message
+func Skipped(msg string, args ...interface{}) Result {
+	return Result{
+		Status:  StatusSkipped,
+		Message: fmt.Sprintf(msg, args...),
+	}
+}
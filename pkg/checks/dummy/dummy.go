@@ -17,15 +17,25 @@ limitations under the License.
 package dummy
 
 import (
+	"context"
+
 	"github.com/galexrt/srcds_controller/pkg/checks"
 	"github.com/galexrt/srcds_controller/pkg/config"
 )
 
 func init() {
-	checks.Checks["dummy"] = Run
+	checks.Register("dummy", New)
+}
+
+// Check dummy check which always fails
+type Check struct{}
+
+// New return a new dummy check
+func New(opts config.CheckOpts) (checks.Check, error) {
+	return &Check{}, nil
 }
 
 // Run run a dummy check on a config.Server
-func Run(check config.Check, server *config.Config) bool {
-	return false
+func (c *Check) Run(ctx context.Context, server *config.Config) checks.Result {
+	return checks.Critical("dummy check always fails")
 }
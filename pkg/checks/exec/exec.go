@@ -0,0 +1,230 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/checks"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/server"
+	"github.com/galexrt/srcds_controller/pkg/util"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	defaultOpts = config.CheckOpts{
+		"timeout": "30s",
+		"shell":   "/bin/sh",
+	}
+)
+
+func init() {
+	checks.Register("exec", New)
+}
+
+// Check exec check running a Nagios / Icinga compatible plugin command
+type Check struct {
+	command string
+	shell   string
+	timeout time.Duration
+}
+
+// New return a new exec check from the given opts
+func New(opts config.CheckOpts) (checks.Check, error) {
+	opts, err := checks.MergeDefaults(opts, defaultOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts["command"] == "" {
+		return nil, fmt.Errorf("no command given for exec check")
+	}
+
+	timeout, err := time.ParseDuration(opts["timeout"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse exec timeout opt. %w", err)
+	}
+
+	return &Check{
+		command: opts["command"],
+		shell:   opts["shell"],
+		timeout: timeout,
+	}, nil
+}
+
+// Run run the configured command for a config.Server and map its exit code
+// to a check status (0 = OK, 1 = WARNING, 2 = CRITICAL, 3 and others = UNKNOWN)
+func (c *Check) Run(ctx context.Context, srv *config.Config) checks.Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	cmd := exec.Command(c.shell, "-c", c.command)
+	cmd.Dir = srv.Server.Path
+	cmd.Env = append(os.Environ(), ServerEnv(srv)...)
+
+	stdout := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	err := RunCommand(ctx, cmd)
+	// A hung plugin is critical like in Nagios, the same as when the checker
+	// check timeout is hit first
+	if ctx.Err() == context.DeadlineExceeded {
+		return checks.Critical("exec check command timed out after %s", c.timeout)
+	}
+
+	exitCode := 0
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return checks.Unknown(fmt.Errorf("failed to run exec check command. %w", err))
+		}
+		exitCode = exitErr.ExitCode()
+	}
+	if stderr.Len() > 0 {
+		log.WithField("server", srv.Server.Name).Debugf("exec check command stderr: %s", stderr.String())
+	}
+
+	result := parseOutput(stdout.String())
+	switch exitCode {
+	case 0:
+		result.Status = checks.StatusOK
+	case 1:
+		result.Status = checks.StatusWarning
+	case 2:
+		result.Status = checks.StatusCritical
+	default:
+		result.Status = checks.StatusUnknown
+	}
+	result.Attributes["exit_code"] = strconv.Itoa(exitCode)
+
+	return result
+}
+
+// RunCommand run the command in its own process group which is killed when
+// the context is done, otherwise child processes of the shell would keep
+// running and holding the output pipes open past the timeout
+func RunCommand(ctx context.Context, cmd *exec.Cmd) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		case <-done:
+		}
+	}()
+
+	err := cmd.Wait()
+	close(done)
+	return err
+}
+
+// parseOutput parse Nagios plugin output, the first line is the message and
+// performance data after the `|` is added to the attributes
+func parseOutput(out string) checks.Result {
+	result := checks.Result{
+		Attributes: map[string]string{},
+	}
+
+	line := strings.TrimSpace(strings.SplitN(out, "\n", 2)[0])
+	parts := strings.SplitN(line, "|", 2)
+	result.Message = strings.TrimSpace(parts[0])
+	if len(parts) < 2 {
+		return result
+	}
+
+	for _, perfData := range splitPerfData(parts[1]) {
+		kv := strings.SplitN(perfData, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value := strings.SplitN(kv[1], ";", 2)[0]
+		result.Attributes[strings.Trim(kv[0], "'")] = value
+	}
+
+	return result
+}
+
+// splitPerfData split performance data by whitespace, labels can be quoted
+// using single quotes to contain whitespace
+func splitPerfData(in string) []string {
+	fields := []string{}
+	field := strings.Builder{}
+	quoted := false
+	for _, r := range in {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+			field.WriteRune(r)
+		case !quoted && (r == ' ' || r == '\t'):
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+// ServerEnv return the environment variables describing the server for
+// commands run by the checker. The address and port are the ones the server
+// is reachable at from the host, depending on the network mode.
+func ServerEnv(srv *config.Config) []string {
+	address, port := srv.Server.Address, strconv.Itoa(srv.Server.Port)
+	if hostPort, err := server.Address(srv); err != nil {
+		log.WithField("server", srv.Server.Name).Warnf("failed to get server address, using configured address. %+v", err)
+	} else if host, p, err := net.SplitHostPort(hostPort); err == nil {
+		address, port = host, p
+	}
+
+	env := []string{
+		fmt.Sprintf("SRCDS_SERVER_NAME=%s", srv.Server.Name),
+		fmt.Sprintf("SRCDS_SERVER_ADDRESS=%s", address),
+		fmt.Sprintf("SRCDS_SERVER_PORT=%s", port),
+		fmt.Sprintf("SRCDS_SERVER_PATH=%s", srv.Server.Path),
+		fmt.Sprintf("SRCDS_SERVER_GAMEID=%d", srv.Server.GameID),
+	}
+	if srv.Docker != nil {
+		env = append(env, fmt.Sprintf("SRCDS_CONTAINER_NAME=%s", util.GetContainerName(srv.Docker.NamePrefix, srv.Server.Name)))
+	}
+	if srv.Server.RCON != nil {
+		env = append(env, fmt.Sprintf("SRCDS_RCON_PASSWORD=%s", srv.Server.RCON.Password))
+	}
+	return env
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/checks"
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+func TestRun(t *testing.T) {
+	srv := &config.Config{
+		Server: &config.Server{
+			Name: "server1",
+		},
+	}
+
+	tests := []struct {
+		command string
+		status  checks.Status
+		message string
+	}{
+		{`echo "PROCS OK: 1 process | procs=1;2;3"`, checks.StatusOK, "PROCS OK: 1 process"},
+		{`echo "WARN"; exit 1`, checks.StatusWarning, "WARN"},
+		{`echo "CRIT $SRCDS_SERVER_NAME"; exit 2`, checks.StatusCritical, "CRIT server1"},
+		{`exit 3`, checks.StatusUnknown, ""},
+		{`exit 42`, checks.StatusUnknown, ""},
+	}
+
+	for _, test := range tests {
+		c, err := New(config.CheckOpts{"command": test.command})
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := c.Run(context.Background(), srv)
+		if result.Status != test.status {
+			t.Errorf("command %q: expected status %s, got %s", test.command, test.status, result.Status)
+		}
+		if result.Message != test.message {
+			t.Errorf("command %q: expected message %q, got %q", test.command, test.message, result.Message)
+		}
+	}
+}
+
+func TestRunTimeoutChildProcess(t *testing.T) {
+	srv := &config.Config{
+		Server: &config.Server{
+			Name: "server1",
+		},
+	}
+
+	// The shell forks sleep, which has to be killed with the shell
+	c, err := New(config.CheckOpts{"command": "sleep 5; echo hi", "timeout": "1s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	result := c.Run(context.Background(), srv)
+	if took := time.Since(start); took > 3*time.Second {
+		t.Errorf("expected check to time out after 1s, took %s", took)
+	}
+	if result.Status != checks.StatusCritical {
+		t.Errorf("expected status %s, got %s", checks.StatusCritical, result.Status)
+	}
+}
+
+func TestParseOutputPerfData(t *testing.T) {
+	result := parseOutput("DB OK | 'query time'=0.2s;1;2 players=12\nlong output")
+	if result.Message != "DB OK" {
+		t.Errorf("unexpected message %q", result.Message)
+	}
+	if result.Attributes["query time"] != "0.2s" || result.Attributes["players"] != "12" {
+		t.Errorf("unexpected attributes %+v", result.Attributes)
+	}
+}
+
+func TestNewWithoutCommand(t *testing.T) {
+	if _, err := New(config.CheckOpts{}); err == nil {
+		t.Fatal("expected error for exec check without command")
+	}
+}
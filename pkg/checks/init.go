@@ -17,7 +17,72 @@ limitations under the License.
 package checks
 
 import (
+	"fmt"
+
 	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/imdario/mergo"
 )
 
-var Checks = map[string]func(check config.Check, server *config.Config) bool{}
+// Checks registry of the available checks by name
+var Checks = map[string]Factory{}
+
+// Factory creates a Check from the given (already defaulted) check options.
+// An error must be returned when the options are invalid.
+type Factory func(opts config.CheckOpts) (Check, error)
+
+// Register add a check factory to the checks registry
+func Register(name string, factory Factory) {
+	Checks[name] = factory
+}
+
+// New create a check for the given check config and server. The check opts
+// are merged with the global check opts (`checks` in the config) and the
+// given defaults of the check before being validated by the check factory.
+func New(check config.Check, srv *config.Config) (Check, error) {
+	factory, ok := Checks[check.Name]
+	if !ok {
+		return nil, fmt.Errorf("unknown check %s for server %s", check.Name, srv.Server.Name)
+	}
+
+	opts := config.CheckOpts{}
+	for k, v := range check.Opts {
+		opts[k] = v
+	}
+	if globalOpts, ok := srv.Checks[check.Name]; ok {
+		if err := mergo.Map(&opts, globalOpts); err != nil {
+			return nil, fmt.Errorf("failed to merge check %s opts with global check opts for server %s. %w", check.Name, srv.Server.Name, err)
+		}
+	}
+
+	c, err := factory(opts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid opts for check %s on server %s. %w", check.Name, srv.Server.Name, err)
+	}
+	return c, nil
+}
+
+// Validate validate all checks of the given server config, the checks have
+// to be registered by importing their packages
+func Validate(srv *config.Config) error {
+	for _, check := range srv.Server.Checks {
+		// Composite checks are evaluated by the checker from other checks
+		if check.Name == config.CompositeCheckName {
+			continue
+		}
+		if _, err := New(check, srv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MergeDefaults merge the given default opts into the check opts
+func MergeDefaults(opts config.CheckOpts, defaults config.CheckOpts) (config.CheckOpts, error) {
+	if opts == nil {
+		opts = config.CheckOpts{}
+	}
+	if err := mergo.Map(&opts, defaults); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
@@ -17,51 +17,185 @@ limitations under the License.
 package rcon
 
 import (
-	"net"
+	"context"
+	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	rcon "github.com/galexrt/go-rcon"
 	"github.com/galexrt/srcds_controller/pkg/checks"
 	"github.com/galexrt/srcds_controller/pkg/config"
-	"github.com/imdario/mergo"
+	srvpkg "github.com/galexrt/srcds_controller/pkg/server"
 	log "github.com/sirupsen/logrus"
 )
 
 var (
 	defaultOpts = config.CheckOpts{
-		"timeout": "30s",
+		"timeout":    "30s",
+		"command":    "maxplayers",
+		"failStatus": "CRITICAL",
 	}
+
+	compareRegex = regexp.MustCompile(`^\s*(==|!=|<=|>=|<|>)\s*(-?[0-9.]+)\s*$`)
 )
 
 func init() {
-	checks.Checks["rcon"] = Run
+	checks.Register("rcon", New)
+}
+
+// Check rcon check, runs a command and optionally asserts on its output
+type Check struct {
+	timeout    string
+	command    string
+	failStatus checks.Status
+
+	match    *regexp.Regexp
+	notMatch *regexp.Regexp
+	capture  *regexp.Regexp
+	operator string
+	value    float64
+}
+
+// New return a new rcon check from the given opts
+func New(opts config.CheckOpts) (checks.Check, error) {
+	opts, err := checks.MergeDefaults(opts, defaultOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := time.ParseDuration(opts["timeout"]); err != nil {
+		return nil, fmt.Errorf("failed to parse rcon timeout opt. %w", err)
+	}
+
+	c := &Check{
+		timeout: opts["timeout"],
+		command: opts["command"],
+	}
+
+	if err := c.failStatus.UnmarshalText([]byte(opts["failStatus"])); err != nil {
+		return nil, fmt.Errorf("failed to parse rcon failStatus opt. %w", err)
+	}
+	if c.failStatus != checks.StatusWarning && c.failStatus != checks.StatusCritical {
+		return nil, fmt.Errorf("rcon failStatus opt must be either WARNING or CRITICAL")
+	}
+
+	if opts["match"] != "" {
+		if c.match, err = regexp.Compile(opts["match"]); err != nil {
+			return nil, fmt.Errorf("failed to compile rcon match opt. %w", err)
+		}
+	}
+	if opts["notMatch"] != "" {
+		if c.notMatch, err = regexp.Compile(opts["notMatch"]); err != nil {
+			return nil, fmt.Errorf("failed to compile rcon notMatch opt. %w", err)
+		}
+	}
+	if opts["capture"] != "" || opts["compare"] != "" {
+		if opts["capture"] == "" || opts["compare"] == "" {
+			return nil, fmt.Errorf("rcon capture and compare opts must be given together")
+		}
+		if c.capture, err = regexp.Compile(opts["capture"]); err != nil {
+			return nil, fmt.Errorf("failed to compile rcon capture opt. %w", err)
+		}
+		if c.capture.NumSubexp() < 1 {
+			return nil, fmt.Errorf("rcon capture opt must contain a capture group")
+		}
+		parts := compareRegex.FindStringSubmatch(opts["compare"])
+		if parts == nil {
+			return nil, fmt.Errorf("failed to parse rcon compare opt %q, expected an operator and a number (e.g., `== 0`)", opts["compare"])
+		}
+		c.operator = parts[1]
+		if c.value, err = strconv.ParseFloat(parts[2], 64); err != nil {
+			return nil, fmt.Errorf("failed to parse rcon compare opt number. %w", err)
+		}
+	}
+
+	return c, nil
 }
 
 // Run run a rcon check on a config.Server
-func Run(check config.Check, server *config.Config) bool {
-	if err := mergo.Map(&check.Opts, defaultOpts); err != nil {
-		log.Fatalf("failed to merge checks opts and rcon check defaults %s", server.Server.Name)
+func (c *Check) Run(ctx context.Context, server *config.Config) checks.Result {
+	if server.Server.RCON == nil {
+		return checks.Unknown(fmt.Errorf("no rcon config for server %s", server.Server.Name))
 	}
 
 	log.Debugf("connecting to server %s using RCON", server.Server.Name)
-	port := strconv.Itoa(server.Server.Port)
-	con, err := rcon.Connect(net.JoinHostPort(server.Server.Address, port), &rcon.ConnectOptions{
+	address, err := srvpkg.Address(server)
+	if err != nil {
+		return checks.Unknown(err)
+	}
+	con, err := rcon.Connect(address, &rcon.ConnectOptions{
 		RCONPassword: server.Server.RCON.Password,
-		Timeout:      check.Opts["timeout"],
+		Timeout:      c.timeout,
 	})
 	if err != nil {
-		log.Errorf("error connecting to server %s using RCON. %+v", server.Server.Name, err)
-		return false
+		return checks.Critical("error connecting to server %s using RCON. %+v", server.Server.Name, err)
 	}
 	defer con.Close()
 
-	out, err := con.Send("maxplayers")
+	out, err := con.Send(c.command)
 	if err != nil {
-		log.Errorf("error executing rcon `maxplayers` command. %+v", err)
-		log.Debugf("rcond `maxplayers` command output: %s", out)
-		return false
+		log.Debugf("rcond `%s` command output: %s", c.command, out)
+		return checks.Critical("error executing rcon `%s` command. %+v", c.command, err)
+	}
+	log.Debugf("rcond `%s` command output: %s", c.command, out)
+
+	return c.evaluate(out)
+}
+
+// evaluate assert the configured expectations on the command output
+func (c *Check) evaluate(out string) checks.Result {
+	fail := func(msg string, args ...interface{}) checks.Result {
+		return checks.Result{
+			Status:  c.failStatus,
+			Message: fmt.Sprintf(msg, args...),
+		}
 	}
-	log.Debugf("rcond `maxplayers` command output: %s", out)
 
-	return true
+	if c.match != nil && !c.match.MatchString(out) {
+		return fail("rcon `%s` command output doesn't match %q", c.command, c.match.String())
+	}
+	if c.notMatch != nil && c.notMatch.MatchString(out) {
+		return fail("rcon `%s` command output matches %q", c.command, c.notMatch.String())
+	}
+
+	if c.capture != nil {
+		parts := c.capture.FindStringSubmatch(out)
+		if parts == nil {
+			return fail("rcon `%s` command output doesn't match capture %q", c.command, c.capture.String())
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil {
+			return fail("rcon `%s` command captured value %q isn't a number", c.command, parts[1])
+		}
+		if !compare(value, c.operator, c.value) {
+			return fail("rcon `%s` command captured value %s, expected %s %s", c.command, parts[1], c.operator, strconv.FormatFloat(c.value, 'f', -1, 64))
+		}
+		result := checks.OK("rcon `%s` command captured value %s", c.command, parts[1])
+		result.Attributes = map[string]string{
+			"value": parts[1],
+		}
+		return result
+	}
+
+	return checks.OK("rcon `%s` command successful", c.command)
+}
+
+func compare(a float64, operator string, b float64) bool {
+	switch operator {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
 }
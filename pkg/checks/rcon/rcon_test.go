@@ -0,0 +1,66 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rcon
+
+import (
+	"testing"
+
+	"github.com/galexrt/srcds_controller/pkg/checks"
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		opts   config.CheckOpts
+		out    string
+		status checks.Status
+	}{
+		{config.CheckOpts{}, "maxplayers is 32", checks.StatusOK},
+		{config.CheckOpts{"match": `ULX`}, "[01] ULX (v3.73)", checks.StatusOK},
+		{config.CheckOpts{"match": `ULX`}, "[01] ULib", checks.StatusCritical},
+		{config.CheckOpts{"notMatch": `Error`, "failStatus": "WARNING"}, "[01] <Error> broken.smx", checks.StatusWarning},
+		{config.CheckOpts{"capture": `"sv_cheats" = "(\d+)"`, "compare": "== 0"}, `"sv_cheats" = "0" ( def. "0" )`, checks.StatusOK},
+		{config.CheckOpts{"capture": `"sv_cheats" = "(\d+)"`, "compare": "== 0"}, `"sv_cheats" = "1" ( def. "0" )`, checks.StatusCritical},
+		{config.CheckOpts{"capture": `players : (\d+) humans`, "compare": ">=1"}, "players : 0 humans, 0 bots", checks.StatusCritical},
+		{config.CheckOpts{"capture": `players : (\d+) humans`, "compare": "<= 64"}, "players : 12 humans, 0 bots", checks.StatusOK},
+	}
+
+	for _, test := range tests {
+		c, err := New(test.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result := c.(*Check).evaluate(test.out); result.Status != test.status {
+			t.Errorf("opts %+v output %q: expected status %s, got %s (%s)", test.opts, test.out, test.status, result.Status, result.Message)
+		}
+	}
+}
+
+func TestNewInvalidOpts(t *testing.T) {
+	for _, opts := range []config.CheckOpts{
+		{"timeout": "abc"},
+		{"match": "("},
+		{"capture": `(\d+)`},
+		{"capture": `\d+`, "compare": "== 1"},
+		{"capture": `(\d+)`, "compare": "= 1"},
+		{"failStatus": "OK"},
+	} {
+		if _, err := New(opts); err == nil {
+			t.Errorf("expected error for opts %+v", opts)
+		}
+	}
+}
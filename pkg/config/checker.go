@@ -17,14 +17,91 @@ limitations under the License.
 package config
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
+// CompositeCheckName name of the composite check which combines the results
+// of other checks of a server
+const CompositeCheckName = "composite"
+
+const (
+	// CompositeModeAll composite check is only healthy when all checks are healthy
+	CompositeModeAll = "all"
+	// CompositeModeAny composite check is healthy when any check is healthy
+	CompositeModeAny = "any"
+)
+
+// DefaultCheckerInterval default interval the checks of a server are run in
+const DefaultCheckerInterval = 30 * time.Second
+
 // Check config for a check, see `pkg/checks/` for available checks
 type Check struct {
-	Limit *Limit    `yaml:"limit"`
-	Name  string    `yaml:"name"`
-	Opts  CheckOpts `yaml:"opts"`
+	ID        string     `yaml:"id"`
+	Limit     *Limit     `yaml:"limit"`
+	Name      string     `yaml:"name"`
+	Opts      CheckOpts  `yaml:"opts"`
+	DependsOn []string   `yaml:"dependsOn"`
+	Composite *Composite `yaml:"composite"`
+	// StartupGracePeriod overrides the server's checker startup grace period for this check
+	StartupGracePeriod time.Duration `yaml:"startupGracePeriod"`
+	// Interval overrides the server's checker interval for this check, the
+	// checker splay is still added
+	Interval time.Duration `yaml:"interval"`
+	// Timeout after which the check is canceled and counted as failed,
+	// defaults to the interval of the check
+	Timeout time.Duration `yaml:"timeout"`
+	// Schedule cron expression (e.g., `*/5 * * * *`, `@hourly`) the check is
+	// run at instead of the interval
+	Schedule string `yaml:"schedule"`
+}
+
+// GetInterval return the interval of the check, falls back to the given
+// checker config interval
+func (c Check) GetInterval(checker *Checker) time.Duration {
+	if c.Interval != 0 {
+		return c.Interval
+	}
+	if checker != nil && checker.Interval != 0 {
+		return checker.Interval
+	}
+	return DefaultCheckerInterval
+}
+
+// GetTimeout return the timeout of the check, defaults to the interval of the
+// check
+func (c Check) GetTimeout(checker *Checker) time.Duration {
+	if c.Timeout != 0 {
+		return c.Timeout
+	}
+	return c.GetInterval(checker)
+}
+
+// GetStartupGracePeriod return the startup grace period of the check, falls
+// back to the given checker config startup grace period
+func (c Check) GetStartupGracePeriod(checker *Checker) time.Duration {
+	if c.StartupGracePeriod != 0 {
+		return c.StartupGracePeriod
+	}
+	if checker != nil {
+		return checker.StartupGracePeriod
+	}
+	return 0
+}
+
+// GetID return the ID of the check, defaults to the check name when no ID is set
+func (c Check) GetID() string {
+	if c.ID != "" {
+		return c.ID
+	}
+	return c.Name
+}
+
+// Composite condition combining the results of other checks of a server
+type Composite struct {
+	Mode   string   `yaml:"mode"`
+	Checks []string `yaml:"checks"`
 }
 
 // CheckOpts options that can be set for a check
@@ -33,20 +110,201 @@ type CheckOpts map[string]string
 // Limit config with limits and actions ot execute when the limits (after or count)
 // have been reached
 type Limit struct {
-	After      time.Duration `yaml:"after"`
-	Count      int64         `yaml:"count"`
-	Actions    []string      `yaml:"actions"`
-	ActionOpts CheckOpts     `yaml:"actionOpts"`
+	After   time.Duration `yaml:"after" json:"after"`
+	Count   int64         `yaml:"count" json:"count"`
+	Actions []string      `yaml:"actions" json:"actions"`
+	// ActionOpts are not exposed in the status API and journal, they can
+	// contain webhook URLs and tokens
+	ActionOpts CheckOpts `yaml:"actionOpts" json:"-"`
 }
 
 // Checker config for the checker.Checker
 type Checker struct {
-	Interval time.Duration `yamk:"interval"`
+	Interval time.Duration `yaml:"interval"`
 	Splay    *Splay        `yaml:"splay"`
+	// StartupGracePeriod time after the server container has been started
+	// during which failed checks are counted but don't trigger actions
+	StartupGracePeriod time.Duration `yaml:"startupGracePeriod"`
+	// RestartBudget automatic restarts allowed per server before the server
+	// is quarantined
+	RestartBudget *RestartBudget `yaml:"restartBudget"`
+	// MaintenanceWindows recurring windows during which no actions are run
+	MaintenanceWindows []*MaintenanceWindow `yaml:"maintenanceWindows"`
+	// DockerEvents reactions to the Docker events of the server container
+	DockerEvents *DockerEvents `yaml:"dockerEvents"`
+}
+
+const (
+	// DockerEventDie the server container died (not stopped intentionally)
+	DockerEventDie = "die"
+	// DockerEventOOM the server container has been OOM killed
+	DockerEventOOM = "oom"
+	// DockerEventUnhealthy the server container health check is unhealthy
+	DockerEventUnhealthy = "unhealthy"
+	// DockerEventKill the server container has been killed, outside of a stop
+	DockerEventKill = "kill"
+	// DockerEventCrashLoop the server container died repeatedly in the crash loop window
+	DockerEventCrashLoop = "crash_loop"
+)
+
+const (
+	// ReactionIgnore don't react to the event
+	ReactionIgnore = "ignore"
+	// ReactionLog only log and journal the event
+	ReactionLog = "log"
+	// ReactionNotify send a notification for the event
+	ReactionNotify = "notify"
+	// ReactionRestart restart the server (includes a notification)
+	ReactionRestart = "restart"
+	// ReactionQuarantine quarantine the server (includes a notification)
+	ReactionQuarantine = "quarantine"
+)
+
+// DockerEvents reactions per Docker event type and crash loop detection
+type DockerEvents struct {
+	// Reactions reaction by event type (`die`, `oom`, `unhealthy`, `kill`, `crash_loop`)
+	Reactions map[string]string `yaml:"reactions"`
+	// CrashLoopCount die events in the CrashLoopWindow after which the
+	// `crash_loop` reaction is used instead of the `die` reaction
+	CrashLoopCount  int           `yaml:"crashLoopCount"`
+	CrashLoopWindow time.Duration `yaml:"crashLoopWindow"`
+}
+
+// GetReaction return the reaction for the event type
+func (d *DockerEvents) GetReaction(eventType string) string {
+	if d == nil {
+		return ReactionIgnore
+	}
+	if reaction, ok := d.Reactions[eventType]; ok {
+		return reaction
+	}
+	return ReactionIgnore
+}
+
+func (d *DockerEvents) verify() error {
+	defaults := map[string]string{
+		DockerEventDie:       ReactionRestart,
+		DockerEventOOM:       ReactionNotify,
+		DockerEventUnhealthy: ReactionNotify,
+		DockerEventKill:      ReactionLog,
+		DockerEventCrashLoop: ReactionQuarantine,
+	}
+	if d.Reactions == nil {
+		d.Reactions = map[string]string{}
+	}
+	for eventType, reaction := range d.Reactions {
+		if _, ok := defaults[eventType]; !ok {
+			return fmt.Errorf("unknown docker event type %s in reactions", eventType)
+		}
+		reaction = strings.ToLower(reaction)
+		switch reaction {
+		case ReactionIgnore, ReactionLog, ReactionNotify, ReactionRestart, ReactionQuarantine:
+		default:
+			return fmt.Errorf("unknown reaction %s for docker event type %s", reaction, eventType)
+		}
+		d.Reactions[eventType] = reaction
+	}
+	for eventType, reaction := range defaults {
+		if _, ok := d.Reactions[eventType]; !ok {
+			d.Reactions[eventType] = reaction
+		}
+	}
+	if d.CrashLoopCount == 0 {
+		d.CrashLoopCount = 3
+	}
+	if d.CrashLoopWindow == 0 {
+		d.CrashLoopWindow = 10 * time.Minute
+	}
+	return nil
+}
+
+// RestartBudget maximum count of automatic restarts (checker actions and
+// Docker die events) in the window. When the budget is used up, the server
+// is quarantined and no further actions are run for it, the alert actions
+// are run once instead. A count of 0 disables the restart budget.
+type RestartBudget struct {
+	Count           int64         `yaml:"count"`
+	Window          time.Duration `yaml:"window"`
+	AlertActions    []string      `yaml:"alertActions"`
+	AlertActionOpts CheckOpts     `yaml:"alertActionOpts"`
 }
 
-// Splay time splay config
+// Splay time splay config, a random amount of seconds between start and end
+// is added to the check interval
 type Splay struct {
 	Start int `yaml:"start"`
 	End   int `yaml:"end"`
 }
+
+func (s *Splay) verify() error {
+	if s.Start < 0 || s.End < 0 {
+		return fmt.Errorf("checker splay start (%d) and end (%d) must not be negative", s.Start, s.End)
+	}
+	if s.Start > s.End {
+		return fmt.Errorf("checker splay start (%d) must not be greater than end (%d)", s.Start, s.End)
+	}
+	return nil
+}
+
+func (c *Config) verifyChecks() error {
+	ids := map[string]bool{}
+	for _, check := range c.Server.Checks {
+		if check.Name == "" {
+			return fmt.Errorf("check without name found")
+		}
+		if ids[check.GetID()] {
+			return fmt.Errorf("duplicate check id %s, set an unique `id` for each check", check.GetID())
+		}
+		ids[check.GetID()] = true
+
+		if check.Interval < 0 || check.Timeout < 0 {
+			return fmt.Errorf("check %s has a negative interval or timeout", check.GetID())
+		}
+		if check.Schedule != "" {
+			if check.Interval != 0 {
+				return fmt.Errorf("check %s has an interval and a schedule set, only one is allowed", check.GetID())
+			}
+			if _, err := ParseCronSchedule(check.Schedule); err != nil {
+				return fmt.Errorf("check %s. %w", check.GetID(), err)
+			}
+		}
+	}
+
+	for _, check := range c.Server.Checks {
+		for _, dep := range check.DependsOn {
+			if dep == check.GetID() {
+				return fmt.Errorf("check %s depends on itself", check.GetID())
+			}
+			if !ids[dep] {
+				return fmt.Errorf("check %s depends on unknown check %s", check.GetID(), dep)
+			}
+		}
+
+		if check.Name != CompositeCheckName {
+			if check.Composite != nil {
+				return fmt.Errorf("check %s has a composite condition but isn't a %s check", check.GetID(), CompositeCheckName)
+			}
+			continue
+		}
+		if check.Composite == nil || len(check.Composite.Checks) == 0 {
+			return fmt.Errorf("composite check %s has no checks given", check.GetID())
+		}
+		check.Composite.Mode = strings.ToLower(check.Composite.Mode)
+		if check.Composite.Mode == "" {
+			check.Composite.Mode = CompositeModeAll
+		}
+		if check.Composite.Mode != CompositeModeAll && check.Composite.Mode != CompositeModeAny {
+			return fmt.Errorf("composite check %s has unknown mode %s", check.GetID(), check.Composite.Mode)
+		}
+		for _, child := range check.Composite.Checks {
+			if child == check.GetID() {
+				return fmt.Errorf("composite check %s contains itself", check.GetID())
+			}
+			if !ids[child] {
+				return fmt.Errorf("composite check %s contains unknown check %s", check.GetID(), child)
+			}
+		}
+	}
+
+	return nil
+}
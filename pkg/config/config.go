@@ -30,11 +30,13 @@ var (
 
 // Config config file struct
 type Config struct {
-	General *General             `yaml:"general"`
-	Docker  *Docker              `yaml:"docker"`
-	Server  *Server              `yaml:"server"`
-	Checker *Checker             `yaml:"checker"`
-	Checks  map[string]CheckOpts `yaml:"checks"`
+	General       *General             `yaml:"general"`
+	Docker        *Docker              `yaml:"docker"`
+	Server        *Server              `yaml:"server"`
+	Checker       *Checker             `yaml:"checker"`
+	Checks        map[string]CheckOpts `yaml:"checks"`
+	Notifications *Notifications       `yaml:"notifications"`
+	StatusPage    *StatusPage          `yaml:"statusPage"`
 }
 
 // Verify verify the config file
@@ -44,7 +46,7 @@ func (c *Config) Verify() error {
 		c.Checker = &Checker{}
 	}
 	if c.Checker.Interval == 0 {
-		c.Checker.Interval = 30 * time.Second
+		c.Checker.Interval = DefaultCheckerInterval
 	}
 	if c.Checker.Splay == nil {
 		c.Checker.Splay = &Splay{
@@ -52,6 +54,28 @@ func (c *Config) Verify() error {
 			End:   20,
 		}
 	}
+	if err := c.Checker.Splay.verify(); err != nil {
+		return err
+	}
+	if c.Checker.RestartBudget == nil {
+		c.Checker.RestartBudget = &RestartBudget{
+			Count: 5,
+		}
+	}
+	if c.Checker.RestartBudget.Window == 0 {
+		c.Checker.RestartBudget.Window = time.Hour
+	}
+	if c.Checker.DockerEvents == nil {
+		c.Checker.DockerEvents = &DockerEvents{}
+	}
+	if err := c.Checker.DockerEvents.verify(); err != nil {
+		return err
+	}
+	for _, window := range c.Checker.MaintenanceWindows {
+		if err := window.verify(); err != nil {
+			return err
+		}
+	}
 
 	// Docker
 	if c.Docker == nil {
@@ -69,6 +93,17 @@ func (c *Config) Verify() error {
 	if c.Docker.TimezoneFile == "" {
 		c.Docker.TimezoneFile = "/etc/timezone"
 	}
+	if c.Docker.HealthCheck == nil {
+		c.Docker.HealthCheck = &HealthCheck{}
+	}
+	if err := c.Docker.HealthCheck.verify(); err != nil {
+		return err
+	}
+	if c.Docker.Security != nil {
+		if err := c.Docker.Security.verify(); err != nil {
+			return err
+		}
+	}
 
 	// General
 	if c.General == nil {
@@ -107,6 +142,29 @@ func (c *Config) Verify() error {
 	if c.Server.Port == 0 {
 		return fmt.Errorf("no server port given")
 	}
+	if c.Server.Network == nil {
+		c.Server.Network = &Network{}
+	}
+	if err := c.Server.Network.verify(c.Server.Port); err != nil {
+		return fmt.Errorf("server %s: %w", c.Server.Name, err)
+	}
+
+	// Checks
+	if err := c.verifyChecks(); err != nil {
+		return fmt.Errorf("server %s: %w", c.Server.Name, err)
+	}
+
+	// Status page
+	if c.StatusPage != nil && c.StatusPage.Timeout == 0 {
+		c.StatusPage.Timeout = 10 * time.Second
+	}
+
+	// Notifications
+	if c.Notifications != nil {
+		if err := c.Notifications.verify(); err != nil {
+			return fmt.Errorf("server %s: %w", c.Server.Name, err)
+		}
+	}
 
 	return nil
 }
@@ -121,8 +179,10 @@ const GlobalConfigPath = "/etc/srcds_controller/config.yaml"
 
 // GlobalConfig global config file always read from `/etc/srcds_controller/config.yaml`
 type GlobalConfig struct {
-	General *General             `yaml:"general"`
-	Docker  *Docker              `yaml:"docker"`
-	Checker *Checker             `yaml:"checker"`
-	Checks  map[string]CheckOpts `yaml:"checks"`
+	General       *General             `yaml:"general"`
+	Docker        *Docker              `yaml:"docker"`
+	Checker       *Checker             `yaml:"checker"`
+	Checks        map[string]CheckOpts `yaml:"checks"`
+	Notifications *Notifications       `yaml:"notifications"`
+	StatusPage    *StatusPage          `yaml:"statusPage"`
 }
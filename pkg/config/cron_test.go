@@ -0,0 +1,54 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	from := time.Date(2021, time.March, 5, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"*/5 * * * *", time.Date(2021, time.March, 5, 10, 10, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2021, time.March, 5, 11, 0, 0, 0, time.UTC)},
+		{"30 4 * * *", time.Date(2021, time.March, 6, 4, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2021, time.March, 7, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1,15 * *", time.Date(2021, time.March, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 0 1 1-2 *", time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		schedule, err := ParseCronSchedule(test.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", test.expr, err)
+		}
+		if next := schedule.Next(from); !next.Equal(test.expected) {
+			t.Errorf("%s: expected %s, got %s", test.expr, test.expected, next)
+		}
+	}
+}
+
+func TestCronScheduleInvalid(t *testing.T) {
+	for _, expr := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCronSchedule(expr); err == nil {
+			t.Errorf("%s: expected error", expr)
+		}
+	}
+}
@@ -16,6 +16,11 @@ limitations under the License.
 
 package config
 
+import (
+	"fmt"
+	"time"
+)
+
 // Docker config options for Docker connection and/or image used.
 type Docker struct {
 	AdditionalEnvVars []string `yaml:"additionalEnvVars"`
@@ -24,4 +29,42 @@ type Docker struct {
 	LocalTimeFile     string   `yaml:"localTimeFile"`
 	NamePrefix        string   `yaml:"namePrefix"`
 	TimezoneFile      string   `yaml:"timezoneFile"`
+	// RecreateOnDrift recreate the stopped server container on start when it
+	// doesn't match the config anymore
+	RecreateOnDrift bool `yaml:"recreateOnDrift"`
+	// HealthCheck Docker HEALTHCHECK of the server container
+	HealthCheck *HealthCheck `yaml:"healthCheck"`
+	// Security security profile of the server container
+	Security *Security `yaml:"security"`
+}
+
+// HealthCheck Docker HEALTHCHECK of the server container, runs
+// `srcds_runner healthcheck` which checks that the server console responds
+type HealthCheck struct {
+	Disabled bool          `yaml:"disabled"`
+	Interval time.Duration `yaml:"interval"`
+	Timeout  time.Duration `yaml:"timeout"`
+	Retries  int           `yaml:"retries"`
+	// StartPeriod time after the server start during which the server is
+	// reported healthy, e.g., while the map is loading
+	StartPeriod time.Duration `yaml:"startPeriod"`
+}
+
+func (h *HealthCheck) verify() error {
+	if h.Interval == 0 {
+		h.Interval = 30 * time.Second
+	}
+	if h.Timeout == 0 {
+		h.Timeout = 10 * time.Second
+	}
+	if h.Retries == 0 {
+		h.Retries = 3
+	}
+	if h.StartPeriod == 0 {
+		h.StartPeriod = 5 * time.Minute
+	}
+	if h.Interval < 0 || h.Timeout < 0 || h.Retries < 0 || h.StartPeriod < 0 {
+		return fmt.Errorf("health check interval, timeout, retries and start period must not be negative")
+	}
+	return nil
 }
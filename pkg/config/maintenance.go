@@ -0,0 +1,91 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindow recurring maintenance window during which the checker
+// doesn't run any actions for the server
+type MaintenanceWindow struct {
+	// Weekdays days on which the window starts (e.g., `mon`, `sat`), every day when empty
+	Weekdays []string `yaml:"weekdays"`
+	// Start local time of day the window starts at in `HH:MM` format
+	Start    string        `yaml:"start"`
+	Duration time.Duration `yaml:"duration"`
+	Reason   string        `yaml:"reason"`
+}
+
+// Active if the maintenance window is active at the given time. Windows
+// crossing midnight are active on the following day as well.
+func (m *MaintenanceWindow) Active(now time.Time) bool {
+	start, err := time.Parse("15:04", m.Start)
+	if err != nil {
+		return false
+	}
+
+	// Check the window starting today and the one which started yesterday
+	for _, day := range []time.Time{now, now.AddDate(0, 0, -1)} {
+		if !m.onWeekday(day.Weekday()) {
+			continue
+		}
+		windowStart := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, now.Location())
+		if !now.Before(windowStart) && now.Before(windowStart.Add(m.Duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MaintenanceWindow) onWeekday(weekday time.Weekday) bool {
+	if len(m.Weekdays) == 0 {
+		return true
+	}
+	for _, day := range m.Weekdays {
+		if strings.HasPrefix(strings.ToLower(weekday.String()), strings.ToLower(day)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MaintenanceWindow) verify() error {
+	if _, err := time.Parse("15:04", m.Start); err != nil {
+		return fmt.Errorf("invalid maintenance window start %q, must be in HH:MM format", m.Start)
+	}
+	if m.Duration <= 0 {
+		return fmt.Errorf("maintenance window starting at %s has no duration", m.Start)
+	}
+	for _, day := range m.Weekdays {
+		if len(day) < 3 {
+			return fmt.Errorf("invalid maintenance window weekday %q, use at least the first three letters", day)
+		}
+		valid := false
+		for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+			if strings.HasPrefix(strings.ToLower(weekday.String()), strings.ToLower(day)) {
+				valid = true
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid maintenance window weekday %q", day)
+		}
+	}
+	return nil
+}
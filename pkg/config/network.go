@@ -0,0 +1,105 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// NetworkModeHost the server container uses the host network (default)
+	NetworkModeHost = "host"
+	// NetworkModeBridge the server container uses the Docker bridge network
+	// with the game, RCON and SourceTV ports published on the host
+	NetworkModeBridge = "bridge"
+	// NetworkModeNamed the server container is attached to the named network
+	NetworkModeNamed = "network"
+)
+
+// Network network mode of the server container
+type Network struct {
+	// Mode one of `host`, `bridge` or `network`
+	Mode string `yaml:"mode"`
+	// Name of the Docker network for mode `network`
+	Name string `yaml:"name"`
+	// HostIP IP the ports are published on, all IPs when empty
+	HostIP string `yaml:"hostIP"`
+	// HostPort host port the game and RCON port (`server.port`) is published
+	// on, defaults to the server port in bridge mode. In mode `network` the
+	// ports are only published when set.
+	HostPort int `yaml:"hostPort"`
+	// SourceTVPort SourceTV port of the server which is published as well
+	SourceTVPort int `yaml:"sourceTVPort"`
+	// SourceTVHostPort host port the SourceTV port is published on, defaults
+	// to the SourceTV port
+	SourceTVHostPort int `yaml:"sourceTVHostPort"`
+}
+
+// Published if the ports of the server are published on the host
+func (n *Network) Published() bool {
+	if n == nil {
+		return false
+	}
+	return n.Mode == NetworkModeBridge || (n.Mode == NetworkModeNamed && n.HostPort != 0)
+}
+
+// DockerNetworkMode return the Docker network mode of the container
+func (n *Network) DockerNetworkMode() string {
+	if n == nil || n.Mode == NetworkModeHost {
+		return NetworkModeHost
+	}
+	if n.Mode == NetworkModeNamed {
+		return n.Name
+	}
+	return n.Mode
+}
+
+func (n *Network) verify(serverPort int) error {
+	n.Mode = strings.ToLower(n.Mode)
+	switch n.Mode {
+	case "":
+		n.Mode = NetworkModeHost
+	case NetworkModeHost, NetworkModeBridge:
+	case NetworkModeNamed:
+		if n.Name == "" {
+			return fmt.Errorf("network mode %s needs a network name", NetworkModeNamed)
+		}
+	default:
+		return fmt.Errorf("unknown network mode %s, must be one of %s, %s or %s", n.Mode, NetworkModeHost, NetworkModeBridge, NetworkModeNamed)
+	}
+
+	// Nothing is published in host mode and in mode `network` without a
+	// host port, reject the publish settings instead of ignoring them
+	if n.Mode == NetworkModeHost && (n.HostIP != "" || n.HostPort != 0 || n.SourceTVPort != 0 || n.SourceTVHostPort != 0) {
+		return fmt.Errorf("network mode %s doesn't publish ports, hostIP, hostPort, sourceTVPort and sourceTVHostPort must not be set", NetworkModeHost)
+	}
+	if n.Mode == NetworkModeNamed && n.HostPort == 0 && (n.HostIP != "" || n.SourceTVPort != 0 || n.SourceTVHostPort != 0) {
+		return fmt.Errorf("network mode %s only publishes ports with a hostPort, hostIP, sourceTVPort and sourceTVHostPort need a hostPort", NetworkModeNamed)
+	}
+	if n.SourceTVHostPort != 0 && n.SourceTVPort == 0 {
+		return fmt.Errorf("network sourceTVHostPort needs a sourceTVPort")
+	}
+
+	if n.Mode == NetworkModeBridge && n.HostPort == 0 {
+		n.HostPort = serverPort
+	}
+	if n.SourceTVPort != 0 && n.SourceTVHostPort == 0 {
+		n.SourceTVHostPort = n.SourceTVPort
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import "testing"
+
+func TestNetworkVerify(t *testing.T) {
+	tests := []struct {
+		network Network
+		valid   bool
+	}{
+		{Network{}, true},
+		{Network{Mode: NetworkModeHost}, true},
+		{Network{Mode: NetworkModeHost, HostPort: 27215}, false},
+		{Network{Mode: NetworkModeHost, HostIP: "192.0.2.20"}, false},
+		{Network{Mode: NetworkModeHost, SourceTVPort: 27020}, false},
+		{Network{Mode: NetworkModeBridge, HostIP: "192.0.2.20", SourceTVPort: 27020}, true},
+		{Network{Mode: NetworkModeBridge, SourceTVHostPort: 27120}, false},
+		{Network{Mode: NetworkModeNamed, Name: "games"}, true},
+		{Network{Mode: NetworkModeNamed, Name: "games", HostPort: 27215, SourceTVPort: 27020}, true},
+		{Network{Mode: NetworkModeNamed, Name: "games", SourceTVPort: 27020}, false},
+		{Network{Mode: NetworkModeNamed}, false},
+		{Network{Mode: "overlay"}, false},
+	}
+
+	for _, test := range tests {
+		network := test.network
+		if err := network.verify(27015); (err == nil) != test.valid {
+			t.Errorf("network %+v: expected valid %t, got error %v", test.network, test.valid, err)
+		}
+	}
+}
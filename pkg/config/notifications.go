@@ -0,0 +1,81 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Notifications config for the notifications sent on lifecycle and checker events
+type Notifications struct {
+	Webhooks []*Webhook `yaml:"webhooks"`
+}
+
+// Webhook a webhook notifications are posted to. The notifications can be
+// routed by event types and servers, empty lists match everything.
+type Webhook struct {
+	Name    string   `yaml:"name"`
+	URL     string   `yaml:"url"`
+	Events  []string `yaml:"events"`
+	Servers []string `yaml:"servers"`
+	// Template Go text/template for the JSON body, defaults to a Discord and
+	// Slack compatible payload
+	Template     string            `yaml:"template"`
+	Headers      map[string]string `yaml:"headers"`
+	Timeout      time.Duration     `yaml:"timeout"`
+	Retries      int               `yaml:"retries"`
+	RetryBackoff time.Duration     `yaml:"retryBackoff"`
+}
+
+// Matches if the webhook should be notified about the event type of the server
+func (w *Webhook) Matches(serverName string, eventType string) bool {
+	return matchesAny(w.Servers, serverName) && matchesAny(w.Events, eventType)
+}
+
+func matchesAny(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, v := range list {
+		if v == value || v == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Notifications) verify() error {
+	for i, webhook := range n.Webhooks {
+		if webhook == nil {
+			return fmt.Errorf("empty notifications webhook at index %d", i)
+		}
+		if webhook.URL == "" {
+			return fmt.Errorf("no url given for notifications webhook %s (index %d)", webhook.Name, i)
+		}
+		if webhook.Timeout == 0 {
+			webhook.Timeout = 10 * time.Second
+		}
+		if webhook.Retries == 0 {
+			webhook.Retries = 3
+		}
+		if webhook.RetryBackoff == 0 {
+			webhook.RetryBackoff = 2 * time.Second
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultCapabilities capabilities added to the server container when no
+// allowlist is configured
+var DefaultCapabilities = []string{"SYS_PTRACE"}
+
+// Security security profile of the server container, can be set in the
+// global config for all servers
+type Security struct {
+	// DropAllCapabilities drop all capabilities except the `capabilities` allowlist
+	DropAllCapabilities bool `yaml:"dropAllCapabilities"`
+	// Capabilities added to (or with `dropAllCapabilities` kept for) the
+	// container, defaults to `SYS_PTRACE`
+	Capabilities []string `yaml:"capabilities"`
+	// ReadOnlyRootfs mount the container root filesystem read-only, a tmpfs
+	// is mounted on /tmp
+	ReadOnlyRootfs bool `yaml:"readOnlyRootfs"`
+	// TmpfsSize size of the /tmp tmpfs for a read-only root filesystem
+	TmpfsSize       string `yaml:"tmpfsSize"`
+	NoNewPrivileges bool   `yaml:"noNewPrivileges"`
+	// SeccompProfile path to a seccomp profile JSON file on the host, or `unconfined`
+	SeccompProfile string `yaml:"seccompProfile"`
+	// PidsLimit maximum number of processes in the container (0 for no limit)
+	PidsLimit int64 `yaml:"pidsLimit"`
+	// MaskedPaths paths in the container which are hidden, a file is masked
+	// with /dev/null and a directory (ending with `/`) with an empty read-only
+	// tmpfs. Paths inside /proc are not allowed.
+	MaskedPaths []string `yaml:"maskedPaths"`
+}
+
+// GetCapabilities return the capabilities to add to the container
+func (s *Security) GetCapabilities() []string {
+	if s == nil || s.Capabilities == nil {
+		return DefaultCapabilities
+	}
+	return s.Capabilities
+}
+
+func (s *Security) verify() error {
+	for i, capability := range s.Capabilities {
+		capability = strings.TrimPrefix(strings.ToUpper(capability), "CAP_")
+		if capability == "" {
+			return fmt.Errorf("empty capability in security capabilities")
+		}
+		s.Capabilities[i] = capability
+	}
+	if !s.ReadOnlyRootfs && s.TmpfsSize != "" {
+		return fmt.Errorf("security tmpfsSize is only used with readOnlyRootfs")
+	}
+	if s.ReadOnlyRootfs && s.TmpfsSize == "" {
+		s.TmpfsSize = "256m"
+	}
+	if s.PidsLimit < 0 {
+		return fmt.Errorf("security pidsLimit must not be negative")
+	}
+	for _, path := range s.MaskedPaths {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("security masked path %s must be absolute", path)
+		}
+		// runc refuses mounts inside /proc, Docker masks the sensitive /proc
+		// paths by default already
+		if path == "/proc" || strings.HasPrefix(path, "/proc/") {
+			return fmt.Errorf("security masked path %s is inside /proc, which can't be masked (Docker masks the sensitive /proc paths by default)", path)
+		}
+	}
+	return nil
+}
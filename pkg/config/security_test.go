@@ -0,0 +1,54 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import "testing"
+
+func TestSecurityVerifyMaskedPaths(t *testing.T) {
+	tests := []struct {
+		path  string
+		valid bool
+	}{
+		{"/etc/shadow", true},
+		{"/sys/firmware/", true},
+		{"etc/shadow", false},
+		{"/proc", false},
+		{"/proc/kcore", false},
+	}
+
+	for _, test := range tests {
+		security := &Security{MaskedPaths: []string{test.path}}
+		if err := security.verify(); (err == nil) != test.valid {
+			t.Errorf("masked path %s: expected valid %t, got error %v", test.path, test.valid, err)
+		}
+	}
+}
+
+func TestSecurityVerifyTmpfsSize(t *testing.T) {
+	security := &Security{TmpfsSize: "64m"}
+	if err := security.verify(); err == nil {
+		t.Error("expected error for tmpfsSize without readOnlyRootfs")
+	}
+
+	security = &Security{ReadOnlyRootfs: true}
+	if err := security.verify(); err != nil {
+		t.Fatalf("unexpected error. %+v", err)
+	}
+	if security.TmpfsSize != "256m" {
+		t.Errorf("expected default tmpfsSize 256m, got %s", security.TmpfsSize)
+	}
+}
@@ -29,6 +29,7 @@ type Server struct {
 	Enabled       bool                 `yaml:"enabled"`
 	Address       string               `yaml:"address"`
 	Port          int                  `yaml:"port"`
+	Network       *Network             `yaml:"network"`
 	MountsDir     string               `yaml:"mountsDir"`
 	Command       string               `yaml:"command"`
 	Flags         []string             `yaml:"flags"`
@@ -0,0 +1,33 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import "time"
+
+// StatusPage config for a Cachet compatible status page API, incidents are
+// opened for the component of the server
+type StatusPage struct {
+	URL         string        `yaml:"url"`
+	Token       string        `yaml:"token"`
+	ComponentID int           `yaml:"componentID"`
+	Timeout     time.Duration `yaml:"timeout"`
+}
+
+// Enabled if the status page is configured for the server
+func (s *StatusPage) Enabled() bool {
+	return s != nil && s.URL != "" && s.ComponentID != 0
+}
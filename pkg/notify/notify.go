@@ -0,0 +1,199 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// EventCheckerAction an action has been run by the checker
+	EventCheckerAction = "checker_action"
+	// EventDockerDie the server container died
+	EventDockerDie = "docker_die"
+	// EventDockerOOM the server container has been OOM killed
+	EventDockerOOM = "docker_oom"
+	// EventDockerUnhealthy the server container health check is unhealthy
+	EventDockerUnhealthy = "docker_unhealthy"
+	// EventDockerKill the server container has been killed outside of a stop
+	EventDockerKill = "docker_kill"
+	// EventCrashLoop the server container died repeatedly
+	EventCrashLoop = "crash_loop"
+	// EventQuarantine the server has been quarantined by the checker
+	EventQuarantine = "quarantine"
+	// EventServerStart the server has been started manually
+	EventServerStart = "server_start"
+	// EventServerStop the server has been stopped manually
+	EventServerStop = "server_stop"
+	// EventServerRestart the server has been restarted manually
+	EventServerRestart = "server_restart"
+)
+
+// DefaultTemplate default webhook body template, compatible with Discord
+// (`content`) and Slack (`text`) incoming webhooks
+const DefaultTemplate = `{"username": "srcds_controller", "content": {{ json .Text }}, "text": {{ json .Text }}}`
+
+// Event a notification event
+type Event struct {
+	Type       string            `json:"type"`
+	Server     string            `json:"server"`
+	Time       time.Time         `json:"time"`
+	Message    string            `json:"message"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Text human readable text of the event
+func (e Event) Text() string {
+	return fmt.Sprintf("[%s] %s: %s", e.Server, e.Type, e.Message)
+}
+
+var (
+	templateFuncs = template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			out, err := json.Marshal(v)
+			return string(out), err
+		},
+	}
+
+	wg sync.WaitGroup
+)
+
+// Send send the event to all webhooks of the server config matching the
+// event and wait for the notifications to be delivered (or failed)
+func Send(ctx context.Context, serverCfg *config.Config, event Event) error {
+	if serverCfg.Notifications == nil {
+		return nil
+	}
+	event.Server = serverCfg.Server.Name
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	errs := []string{}
+	for _, webhook := range serverCfg.Notifications.Webhooks {
+		if !webhook.Matches(event.Server, event.Type) {
+			continue
+		}
+		if err := sendWebhook(ctx, webhook, event); err != nil {
+			log.WithField("server", event.Server).Errorf("failed to send %s notification to webhook %s. %+v", event.Type, webhook.Name, err)
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to send notifications: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// SendAsync send the event in the background, errors are logged. Use Wait
+// to wait for all notifications sent in the background.
+func SendAsync(serverCfg *config.Config, event Event) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		Send(context.Background(), serverCfg, event)
+	}()
+}
+
+// Wait wait for all notifications sent in the background
+func Wait() {
+	wg.Wait()
+}
+
+// Render render the body of the webhook for the event
+func Render(webhook *config.Webhook, event Event) ([]byte, error) {
+	tmplText := webhook.Template
+	if tmplText == "" {
+		tmplText = DefaultTemplate
+	}
+	tmpl, err := template.New("webhook").Funcs(templateFuncs).Parse(tmplText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse webhook template. %w", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, event); err != nil {
+		return nil, fmt.Errorf("failed to render webhook template. %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func sendWebhook(ctx context.Context, webhook *config.Webhook, event Event) error {
+	body, err := Render(webhook, event)
+	if err != nil {
+		return err
+	}
+
+	backoff := webhook.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := post(ctx, webhook, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= webhook.Retries {
+			return err
+		}
+
+		log.WithField("server", event.Server).Debugf("retrying webhook %s in %s. %+v", webhook.Name, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// post post the body to the webhook, returns if the request should be retried
+func post(ctx context.Context, webhook *config.Webhook, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhook.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range webhook.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return false, nil
+	}
+	out, _ := ioutil.ReadAll(resp.Body)
+	err = fmt.Errorf("webhook returned status %d (response body: %s)", resp.StatusCode, strings.TrimSpace(string(out)))
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
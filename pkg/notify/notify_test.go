@@ -0,0 +1,111 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+func TestRenderDefaultTemplate(t *testing.T) {
+	out, err := Render(&config.Webhook{}, Event{
+		Type:    EventDockerDie,
+		Server:  "server1",
+		Message: `server container "died"`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := map[string]string{}
+	if err := json.Unmarshal(out, &body); err != nil {
+		t.Fatalf("default template didn't render valid JSON. %+v (body: %s)", err, out)
+	}
+	expected := `[server1] docker_die: server container "died"`
+	if body["content"] != expected || body["text"] != expected {
+		t.Errorf("expected content and text %q, got %+v", expected, body)
+	}
+}
+
+func TestSendRouting(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer srv.Close()
+
+	serverCfg := &config.Config{
+		Server: &config.Server{
+			Name: "server1",
+		},
+		Notifications: &config.Notifications{
+			Webhooks: []*config.Webhook{
+				{URL: srv.URL, Timeout: time.Second},
+				{URL: srv.URL, Timeout: time.Second, Events: []string{EventQuarantine}},
+				{URL: srv.URL, Timeout: time.Second, Servers: []string{"server2"}},
+			},
+		},
+	}
+
+	if err := Send(context.Background(), serverCfg, Event{Type: EventServerStop}); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("expected 1 webhook request for %s, got %d", EventServerStop, got)
+	}
+	if err := Send(context.Background(), serverCfg, Event{Type: EventQuarantine}); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("expected 3 webhook requests in total, got %d", got)
+	}
+}
+
+func TestSendRetry(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	serverCfg := &config.Config{
+		Server: &config.Server{
+			Name: "server1",
+		},
+		Notifications: &config.Notifications{
+			Webhooks: []*config.Webhook{
+				{URL: srv.URL, Timeout: time.Second, Retries: 3, RetryBackoff: 10 * time.Millisecond},
+			},
+		},
+	}
+
+	if err := Send(context.Background(), serverCfg, Event{Type: EventServerStart}); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("expected 3 webhook requests, got %d", got)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Countdown countdown before a server restart / stop, the remaining time is
+// announced on the servers
+type Countdown struct {
+	Duration time.Duration
+	// AnnounceMinutes command template announcing the remaining minutes (`%d`)
+	// every full minute when EveryMinute is set
+	AnnounceMinutes string
+	// AnnounceSeconds command template announcing the remaining seconds (`%d`)
+	// at the given Times, or every second when EverySecond is set
+	AnnounceSeconds string
+	EveryMinute     bool
+	EverySecond     bool
+	Times           map[int]bool
+}
+
+// CheckAnnounceTemplate check that the announce command template takes the
+// remaining time as its only `%d` verb, an empty template is valid
+func CheckAnnounceTemplate(template string) error {
+	if template == "" {
+		return nil
+	}
+	if !strings.Contains(template, "%d") || strings.Contains(fmt.Sprintf(template, 1), "%!") {
+		return fmt.Errorf("announce template %q must contain a single %%d for the remaining time", template)
+	}
+	return nil
+}
+
+// Run count down and announce the remaining time on the servers, returns
+// when the countdown is over or the context is done
+func (c *Countdown) Run(ctx context.Context, servers []*config.Config) error {
+	secsTotal := int(c.Duration.Seconds())
+	timeLoggerCoolDown := 15
+	for secsRemaining := secsTotal; secsRemaining > 0; secsRemaining-- {
+		mins := secsRemaining / 60
+		switch {
+		case c.EveryMinute && secsRemaining%60 == 0:
+			log.Info("countdown: another minute is over")
+			if c.AnnounceMinutes != "" {
+				announce(servers, fmt.Sprintf(c.AnnounceMinutes, mins))
+			}
+		case c.Times[secsRemaining] || c.EverySecond:
+			log.Debug("countdown: need to announce")
+			if c.AnnounceSeconds != "" {
+				announce(servers, fmt.Sprintf(c.AnnounceSeconds, secsRemaining))
+			}
+		}
+		if timeLoggerCoolDown == 15 || timeLoggerCoolDown == 0 {
+			log.Infof("countdown: remaining: %d seconds, total: %d seconds", secsRemaining, secsTotal)
+			timeLoggerCoolDown = 15
+		}
+		timeLoggerCoolDown--
+
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return fmt.Errorf("countdown cancelled. %w", ctx.Err())
+		}
+	}
+	return nil
+}
+
+// announce send the command to the servers in parallel, errors are logged
+func announce(servers []*config.Config, command string) {
+	wg := sync.WaitGroup{}
+	for _, serverCfg := range servers {
+		wg.Add(1)
+		go func(cfg *config.Config) {
+			defer wg.Done()
+			if err := SendCommand(cfg, []string{command}); err != nil {
+				log.WithField("server", cfg.Server.Name).Errorf("failed to announce countdown. %+v", err)
+			}
+		}(serverCfg)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import "testing"
+
+func TestCheckAnnounceTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		valid    bool
+	}{
+		{"", true},
+		{"say Server restart in %d second(s)!", true},
+		{"say Server restart soon!", false},
+		{"say %s restart in %d second(s)!", false},
+		{"say Server restart in %d second(s), %d", false},
+	}
+
+	for _, test := range tests {
+		if err := CheckAnnounceTemplate(test.template); (err == nil) != test.valid {
+			t.Errorf("template %q: expected valid %t, got error %v", test.template, test.valid, err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/util"
+)
+
+// SpecChange a difference between the server container and its config
+type SpecChange struct {
+	Field   string
+	Current string
+	Desired string
+}
+
+// SpecDiff differences between the server container and the container spec
+// computed from the config
+type SpecDiff struct {
+	Server  string
+	Exists  bool
+	Running bool
+	// Drifted if the spec hash of the container doesn't match the config
+	Drifted     bool
+	CurrentHash string
+	DesiredHash string
+	Changes     []SpecChange
+}
+
+// Diff compare the server container with the container spec computed from
+// the config
+func Diff(serverCfg *config.Config) (*SpecDiff, error) {
+	spec, err := DesiredSpec(serverCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &SpecDiff{
+		Server:      serverCfg.Server.Name,
+		DesiredHash: spec.Config.Labels[SpecHashLabel],
+	}
+
+	ctx := context.Background()
+	cont, err := DockerCli.ContainerInspect(ctx, util.GetContainerName(serverCfg.Docker.NamePrefix, serverCfg.Server.Name))
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return diff, nil
+		}
+		return nil, err
+	}
+	diff.Exists = true
+	diff.Running = cont.State != nil && cont.State.Running
+	if cont.Config == nil || cont.HostConfig == nil {
+		return nil, fmt.Errorf("server %s container inspect returned no config", serverCfg.Server.Name)
+	}
+	diff.CurrentHash = cont.Config.Labels[SpecHashLabel]
+	diff.Drifted = diff.CurrentHash != diff.DesiredHash
+
+	// Env vars from the image are set on the container as well
+	var imageEnv []string
+	image, _, err := DockerCli.ImageInspectWithRaw(ctx, cont.Image)
+	if err == nil && image.Config != nil {
+		imageEnv = image.Config.Env
+	}
+
+	diff.Changes = compareSpec(spec, cont.Config, cont.HostConfig, imageEnv)
+	return diff, nil
+}
+
+// compareSpec list the differences between the desired spec and the config of
+// an existing container
+func compareSpec(spec *ContainerSpec, contCfg *container.Config, hostCfg *container.HostConfig, imageEnv []string) []SpecChange {
+	changes := []SpecChange{}
+	compare := func(field string, current string, desired string) {
+		if current != desired {
+			changes = append(changes, SpecChange{
+				Field:   field,
+				Current: current,
+				Desired: desired,
+			})
+		}
+	}
+
+	compare("image", contCfg.Image, spec.Config.Image)
+	compare("user", contCfg.User, spec.Config.User)
+	compare("workingDir", contCfg.WorkingDir, spec.Config.WorkingDir)
+	compare("hostname", contCfg.Hostname, spec.Config.Hostname)
+	compare("healthcheck", formatHealthConfig(contCfg.Healthcheck), formatHealthConfig(spec.Config.Healthcheck))
+
+	// Env vars
+	current := map[string]bool{}
+	for _, env := range contCfg.Env {
+		current[env] = true
+	}
+	desired := map[string]bool{}
+	for _, env := range spec.Config.Env {
+		desired[env] = true
+		if !current[env] {
+			compare("env", "", env)
+		}
+	}
+	fromImage := map[string]bool{}
+	for _, env := range imageEnv {
+		fromImage[env] = true
+	}
+	for _, env := range contCfg.Env {
+		if !desired[env] && !fromImage[env] {
+			compare("env", env, "")
+		}
+	}
+
+	// Mounts
+	currentMounts := map[string]bool{}
+	for _, m := range hostCfg.Mounts {
+		currentMounts[formatMount(m)] = true
+	}
+	desiredMounts := map[string]bool{}
+	for _, m := range spec.HostConfig.Mounts {
+		desiredMounts[formatMount(m)] = true
+		if !currentMounts[formatMount(m)] {
+			compare("mount", "", formatMount(m))
+		}
+	}
+	for _, m := range hostCfg.Mounts {
+		if !desiredMounts[formatMount(m)] {
+			compare("mount", formatMount(m), "")
+		}
+	}
+
+	compare("networkMode", string(hostCfg.NetworkMode), string(spec.HostConfig.NetworkMode))
+	compare("ports", formatPortBindings(hostCfg.PortBindings), formatPortBindings(spec.HostConfig.PortBindings))
+	compare("restartPolicy", hostCfg.RestartPolicy.Name, spec.HostConfig.RestartPolicy.Name)
+	compare("capAdd", formatList(hostCfg.CapAdd), formatList(spec.HostConfig.CapAdd))
+	compare("capDrop", formatList(hostCfg.CapDrop), formatList(spec.HostConfig.CapDrop))
+	compare("readonlyRootfs", fmt.Sprintf("%t", hostCfg.ReadonlyRootfs), fmt.Sprintf("%t", spec.HostConfig.ReadonlyRootfs))
+	compare("securityOpt", formatSecurityOpts(hostCfg.SecurityOpt), formatSecurityOpts(spec.HostConfig.SecurityOpt))
+	compare("tmpfs", formatTmpfs(hostCfg.Tmpfs), formatTmpfs(spec.HostConfig.Tmpfs))
+
+	// Resources
+	currentRes := reflect.ValueOf(hostCfg.Resources)
+	desiredRes := reflect.ValueOf(spec.HostConfig.Resources)
+	for i := 0; i < desiredRes.NumField(); i++ {
+		c := normalizeValue(currentRes.Field(i))
+		d := normalizeValue(desiredRes.Field(i))
+		if !reflect.DeepEqual(c, d) {
+			compare("resources."+desiredRes.Type().Field(i).Name, fmt.Sprintf("%v", c), fmt.Sprintf("%v", d))
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+func formatMount(m mount.Mount) string {
+	out := fmt.Sprintf("%s:%s", m.Source, m.Target)
+	if m.ReadOnly {
+		out += ":ro"
+	}
+	return out
+}
+
+func formatHealthConfig(h *container.HealthConfig) string {
+	if h == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (interval %s, timeout %s, retries %d)", strings.Join(h.Test, " "), h.Interval, h.Timeout, h.Retries)
+}
+
+func formatList(list []string) string {
+	sorted := append([]string{}, list...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// normalizeValue dereference pointers and treat nil / empty values as the zero
+// value so unset and defaulted fields compare equal
+func normalizeValue(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Zero(v.Type().Elem()).Interface()
+		}
+		v = v.Elem()
+	}
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
+		return nil
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Ptr {
+		out := []interface{}{}
+		for i := 0; i < v.Len(); i++ {
+			out = append(out, normalizeValue(v.Index(i)))
+		}
+		return out
+	}
+	return v.Interface()
+}
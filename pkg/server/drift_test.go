@@ -0,0 +1,101 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/util"
+)
+
+func testServerCfg() *config.Config {
+	return &config.Config{
+		Docker: &config.Docker{
+			Image:         util.StringPointer("galexrt/srcds_controller:runner-latest"),
+			LocalTimeFile: "/etc/localtime",
+			TimezoneFile:  "/etc/timezone",
+		},
+		Server: &config.Server{
+			Name: "server1",
+			Path: "/home/gameserver/server1",
+		},
+	}
+}
+
+func TestDesiredSpecHash(t *testing.T) {
+	spec, err := DesiredSpec(testServerCfg())
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := DesiredSpec(testServerCfg())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Config.Labels[SpecHashLabel] == "" || spec.Config.Labels[SpecHashLabel] != again.Config.Labels[SpecHashLabel] {
+		t.Fatalf("expected a stable spec hash, got %q and %q", spec.Config.Labels[SpecHashLabel], again.Config.Labels[SpecHashLabel])
+	}
+
+	cfg := testServerCfg()
+	cfg.Docker.AdditionalEnvVars = []string{"FOO=bar"}
+	changed, err := DesiredSpec(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Config.Labels[SpecHashLabel] == changed.Config.Labels[SpecHashLabel] {
+		t.Error("expected spec hash to change with the config")
+	}
+}
+
+func TestCompareSpec(t *testing.T) {
+	spec, err := DesiredSpec(testServerCfg())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contCfg := *spec.Config
+	hostCfg := *spec.HostConfig
+	// Env from the image is not a difference
+	contCfg.Env = append([]string{"PATH=/usr/bin"}, contCfg.Env...)
+	if changes := compareSpec(spec, &contCfg, &hostCfg, []string{"PATH=/usr/bin"}); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", changes)
+	}
+
+	contCfg.Image = "galexrt/srcds_controller:runner-old"
+	contCfg.Env = append(contCfg.Env, "OLD=1")
+	hostCfg.Mounts = hostCfg.Mounts[1:]
+	hostCfg.Resources = container.Resources{Memory: 1024}
+	changes := compareSpec(spec, &contCfg, &hostCfg, []string{"PATH=/usr/bin"})
+
+	expected := map[string]bool{
+		"env":               true,
+		"image":             true,
+		"mount":             true,
+		"resources.Memory":  true,
+		"resources.Ulimits": true,
+	}
+	for _, change := range changes {
+		if !expected[change.Field] {
+			t.Errorf("unexpected change %+v", change)
+		}
+		delete(expected, change.Field)
+	}
+	if len(expected) != 0 {
+		t.Errorf("missing changes for %v", expected)
+	}
+}
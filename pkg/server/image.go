@@ -0,0 +1,96 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// ImageStatus image the server container runs compared to the configured image
+type ImageStatus struct {
+	Server string
+	Image  string
+	// Exists if the server container exists
+	Exists bool
+	// Current image ID (digest) the server container runs
+	Current string
+	// Latest image ID (digest) of the configured image on the machine
+	Latest string
+	// RepoDigest registry digest of the configured image
+	RepoDigest string
+}
+
+// Outdated if the server container doesn't run the latest configured image
+func (s *ImageStatus) Outdated() bool {
+	return s.Exists && s.Latest != "" && s.Current != s.Latest
+}
+
+// PullImage pull the image from its registry
+func PullImage(ctx context.Context, image string) error {
+	log.Infof("pulling image %s ...", image)
+	out, err := DockerCli.ImagePull(ctx, image, types.ImagePullOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to pull image %s. %w", image, err)
+	}
+	defer out.Close()
+
+	if err := jsonmessage.DisplayJSONMessagesStream(out, ioutil.Discard, 0, false, nil); err != nil {
+		return fmt.Errorf("failed to pull image %s. %w", image, err)
+	}
+	log.Infof("pulled image %s", image)
+	return nil
+}
+
+// GetImageStatus compare the image the server container runs with the
+// configured image on the machine
+func GetImageStatus(ctx context.Context, serverCfg *config.Config) (*ImageStatus, error) {
+	status := &ImageStatus{
+		Server: serverCfg.Server.Name,
+		Image:  *serverCfg.Docker.Image,
+	}
+
+	image, _, err := DockerCli.ImageInspectWithRaw(ctx, status.Image)
+	if err != nil && !client.IsErrNotFound(err) {
+		return nil, err
+	} else if err == nil {
+		status.Latest = image.ID
+		if len(image.RepoDigests) > 0 {
+			status.RepoDigest = image.RepoDigests[0]
+		}
+	}
+
+	cont, err := DockerCli.ContainerInspect(ctx, util.GetContainerName(serverCfg.Docker.NamePrefix, serverCfg.Server.Name))
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return status, nil
+		}
+		return nil, err
+	}
+	status.Exists = true
+	status.Current = cont.Image
+
+	return status, nil
+}
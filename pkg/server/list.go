@@ -31,11 +31,12 @@ import (
 // List list the servers from the config
 func List() error {
 	w := tabwriter.NewWriter(os.Stdout, 1, 0, 1, ' ', tabwriter.Debug)
-	fmt.Fprintln(w, "Name\tPort\tStatus\tPath")
+	fmt.Fprintln(w, "Name\tPort\tStatus\tHealth\tPath")
 	for _, serverCfg := range userconfig.Cfg.Servers {
 		containerName := util.GetContainerName(serverCfg.Docker.NamePrefix, serverCfg.Server.Name)
 		cont, err := DockerCli.ContainerInspect(context.Background(), containerName)
 		status := "Not Running"
+		health := "-"
 		if err != nil {
 			if !client.IsErrNotFound(err) {
 				return err
@@ -43,8 +44,11 @@ func List() error {
 		}
 		if cont.ContainerJSONBase != nil {
 			status = cont.State.Status
+			if cont.State.Health != nil && cont.State.Running {
+				health = cont.State.Health.Status
+			}
 		}
-		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", serverCfg.Server.Name, serverCfg.Server.Port, status, filepath.Dir(serverCfg.Server.Path))
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", serverCfg.Server.Name, serverCfg.Server.Port, status, health, filepath.Dir(serverCfg.Server.Path))
 	}
 	return w.Flush()
 }
@@ -17,54 +17,132 @@ limitations under the License.
 package server
 
 import (
+	"bufio"
 	"context"
-	"fmt"
 	"io"
-	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/galexrt/srcds_controller/pkg/config"
 	"github.com/galexrt/srcds_controller/pkg/util"
 	log "github.com/sirupsen/logrus"
 )
 
-// Logs show / stream the logs of a server
-func Logs(ctx context.Context, serverCfg *config.Config, since time.Duration, tail int, follow bool) (*exec.Cmd, io.ReadCloser, io.ReadCloser, error) {
-	log.Infof("showing logs of server %s", serverCfg.Server.Name)
+const (
+	// StreamStdout log line from the stdout of the server
+	StreamStdout = "stdout"
+	// StreamStderr log line from the stderr of the server
+	StreamStderr = "stderr"
+)
 
-	cont, err := DockerCli.ContainerInspect(context.Background(), util.GetContainerName(serverCfg.Docker.NamePrefix, serverCfg.Server.Name))
-	if err != nil {
-		return nil, nil, nil, err
+// LogLine a log line of a server container
+type LogLine struct {
+	Server    string
+	Stream    string
+	Timestamp time.Time
+	Text      string
+}
+
+// LogOptions which logs to stream, when since is set, tail is ignored
+type LogOptions struct {
+	Since  time.Duration
+	Tail   int
+	Follow bool
+}
+
+// LogStream stream the log lines of a server container. The channel is
+// closed when the logs end (follow disabled or container stopped) or the
+// context is canceled.
+func LogStream(ctx context.Context, serverCfg *config.Config, opts LogOptions) (<-chan LogLine, error) {
+	logger := log.WithField("server", serverCfg.Server.Name)
+
+	logsOpts := types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+		Follow:     opts.Follow,
+	}
+	if opts.Since != 0 {
+		logsOpts.Since = opts.Since.String()
+	} else if opts.Tail != 0 {
+		logsOpts.Tail = strconv.Itoa(opts.Tail)
 	}
 
-	args := []string{"logs"}
-	if follow {
-		args = append(args, "--follow")
+	rc, err := DockerCli.ContainerLogs(ctx, util.GetContainerName(serverCfg.Docker.NamePrefix, serverCfg.Server.Name), logsOpts)
+	if err != nil {
+		return nil, err
 	}
 
-	if since != 0*time.Millisecond {
-		args = append(args, fmt.Sprintf("--since=%s", since.String()))
-	} else if tail != 0 {
-		args = append(args, fmt.Sprintf("--tail=%d", tail))
+	lines := make(chan LogLine)
+	stdoutR, stdoutW := io.Pipe()
+	stderrR, stderrW := io.Pipe()
+
+	go func() {
+		defer rc.Close()
+		_, err := stdcopy.StdCopy(stdoutW, stderrW, rc)
+		if err != nil && ctx.Err() == nil {
+			logger.Errorf("error during server logs demultiplexing. %+v", err)
+		}
+		stdoutW.CloseWithError(err)
+		stderrW.CloseWithError(err)
+	}()
+	go func() {
+		<-ctx.Done()
+		rc.Close()
+	}()
+
+	wg := sync.WaitGroup{}
+	for stream, r := range map[string]*io.PipeReader{
+		StreamStdout: stdoutR,
+		StreamStderr: stderrR,
+	} {
+		wg.Add(1)
+		go func(stream string, r *io.PipeReader) {
+			defer wg.Done()
+			defer r.Close()
+
+			scanner := bufio.NewScanner(r)
+			scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+			for scanner.Scan() {
+				line := parseLogLine(scanner.Text())
+				line.Server = serverCfg.Server.Name
+				line.Stream = stream
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err := scanner.Err(); err != nil && ctx.Err() == nil {
+				logger.Debugf("error during server logs line scanning. %+v", err)
+			}
+		}(stream, r)
 	}
 
-	args = append(args, cont.ID)
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
 
-	cmd := exec.CommandContext(ctx, "docker", args...)
+	return lines, nil
+}
 
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to get stdout logs. %+v", err)
+// parseLogLine split the timestamp prefix added by Docker from the text
+func parseLogLine(text string) LogLine {
+	i := strings.IndexByte(text, ' ')
+	if i == -1 {
+		return LogLine{Text: text}
 	}
-
-	stderr, err := cmd.StderrPipe()
+	timestamp, err := time.Parse(time.RFC3339Nano, text[:i])
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to get stderr logs. %+v", err)
+		return LogLine{Text: text}
 	}
-
-	if err := cmd.Start(); err != nil {
-		return nil, nil, nil, err
+	return LogLine{
+		Timestamp: timestamp,
+		Text:      text[i+1:],
 	}
-
-	return cmd, stdout, stderr, nil
 }
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	"github.com/galexrt/srcds_controller/pkg/util"
+)
+
+// portBindings return the exposed ports and their host bindings of the server,
+// the game port is published for UDP (game) and TCP (RCON)
+func portBindings(serverCfg *config.Config) (nat.PortSet, nat.PortMap) {
+	network := serverCfg.Server.Network
+	exposed := nat.PortSet{}
+	bindings := nat.PortMap{}
+	add := func(proto string, port int, hostPort int) {
+		p := nat.Port(fmt.Sprintf("%d/%s", port, proto))
+		exposed[p] = struct{}{}
+		bindings[p] = []nat.PortBinding{
+			{
+				HostIP:   network.HostIP,
+				HostPort: strconv.Itoa(hostPort),
+			},
+		}
+	}
+
+	add("udp", serverCfg.Server.Port, network.HostPort)
+	add("tcp", serverCfg.Server.Port, network.HostPort)
+	if network.SourceTVPort != 0 {
+		add("udp", network.SourceTVPort, network.SourceTVHostPort)
+	}
+	return exposed, bindings
+}
+
+// Address return the `HOST:PORT` address the game and RCON port of the server
+// is reachable at from the host, depending on the network mode either the
+// server address and port, the published host port or the container IP
+func Address(serverCfg *config.Config) (string, error) {
+	network := serverCfg.Server.Network
+	if network == nil || network.Mode == config.NetworkModeHost {
+		return net.JoinHostPort(serverCfg.Server.Address, strconv.Itoa(serverCfg.Server.Port)), nil
+	}
+
+	if network.Published() {
+		host := serverCfg.Server.Address
+		if network.HostIP != "" && network.HostIP != "0.0.0.0" && network.HostIP != "::" {
+			host = network.HostIP
+		}
+		return net.JoinHostPort(host, strconv.Itoa(network.HostPort)), nil
+	}
+
+	cont, err := DockerCli.ContainerInspect(context.Background(), util.GetContainerName(serverCfg.Docker.NamePrefix, serverCfg.Server.Name))
+	if err != nil {
+		return "", fmt.Errorf("failed to get server %s container address. %w", serverCfg.Server.Name, err)
+	}
+	if cont.NetworkSettings != nil {
+		if endpoint, ok := cont.NetworkSettings.Networks[network.DockerNetworkMode()]; ok && endpoint.IPAddress != "" {
+			return net.JoinHostPort(endpoint.IPAddress, strconv.Itoa(serverCfg.Server.Port)), nil
+		}
+	}
+	return "", fmt.Errorf("server %s container has no IP address in network %s", serverCfg.Server.Name, network.DockerNetworkMode())
+}
+
+func formatPortBindings(bindings nat.PortMap) string {
+	out := []string{}
+	for port, hostBindings := range bindings {
+		for _, binding := range hostBindings {
+			out = append(out, fmt.Sprintf("%s:%s->%s", binding.HostIP, binding.HostPort, port))
+		}
+	}
+	sort.Strings(out)
+	return formatList(out)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+func TestAddress(t *testing.T) {
+	tests := []struct {
+		network  *config.Network
+		expected string
+	}{
+		{nil, "192.0.2.10:27015"},
+		{&config.Network{Mode: config.NetworkModeHost}, "192.0.2.10:27015"},
+		{&config.Network{Mode: config.NetworkModeBridge, HostPort: 27115}, "192.0.2.10:27115"},
+		{&config.Network{Mode: config.NetworkModeNamed, Name: "games", HostIP: "192.0.2.20", HostPort: 27215}, "192.0.2.20:27215"},
+	}
+	for _, test := range tests {
+		cfg := testServerCfg()
+		cfg.Server.Address = "192.0.2.10"
+		cfg.Server.Port = 27015
+		cfg.Server.Network = test.network
+		address, err := Address(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if address != test.expected {
+			t.Errorf("network %+v: expected address %s, got %s", test.network, test.expected, address)
+		}
+	}
+}
+
+func TestDesiredSpecBridge(t *testing.T) {
+	cfg := testServerCfg()
+	cfg.Server.Port = 27015
+	cfg.Server.Network = &config.Network{
+		Mode:             config.NetworkModeBridge,
+		HostPort:         27115,
+		SourceTVPort:     27020,
+		SourceTVHostPort: 27120,
+	}
+	spec, err := DesiredSpec(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if spec.HostConfig.NetworkMode != "bridge" {
+		t.Errorf("expected bridge network mode, got %s", spec.HostConfig.NetworkMode)
+	}
+	expected := map[nat.Port]string{
+		"27015/udp": "27115",
+		"27015/tcp": "27115",
+		"27020/udp": "27120",
+	}
+	if len(spec.HostConfig.PortBindings) != len(expected) {
+		t.Fatalf("expected %d port bindings, got %+v", len(expected), spec.HostConfig.PortBindings)
+	}
+	for port, hostPort := range expected {
+		bindings := spec.HostConfig.PortBindings[port]
+		if len(bindings) != 1 || bindings[0].HostPort != hostPort {
+			t.Errorf("port %s: expected host port %s, got %+v", port, hostPort, bindings)
+		}
+		if _, ok := spec.Config.ExposedPorts[port]; !ok {
+			t.Errorf("port %s not exposed", port)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/strslice"
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+// applySecurity apply the security profile to the host config of the server
+// container
+func applySecurity(security *config.Security, hostCfg *container.HostConfig) error {
+	hostCfg.CapAdd = strslice.StrSlice(security.GetCapabilities())
+	if security == nil {
+		return nil
+	}
+
+	if security.DropAllCapabilities {
+		hostCfg.CapDrop = strslice.StrSlice{"ALL"}
+	}
+
+	if security.ReadOnlyRootfs {
+		hostCfg.ReadonlyRootfs = true
+		hostCfg.Tmpfs = map[string]string{
+			"/tmp": fmt.Sprintf("rw,nosuid,nodev,size=%s", security.TmpfsSize),
+		}
+	}
+
+	if security.NoNewPrivileges {
+		hostCfg.SecurityOpt = append(hostCfg.SecurityOpt, "no-new-privileges")
+	}
+	if security.SeccompProfile != "" {
+		profile := security.SeccompProfile
+		// The Docker API takes the profile itself and not a path to it
+		if profile != "unconfined" {
+			out, err := ioutil.ReadFile(security.SeccompProfile)
+			if err != nil {
+				return fmt.Errorf("failed to read seccomp profile %s. %w", security.SeccompProfile, err)
+			}
+			profile = string(out)
+		}
+		hostCfg.SecurityOpt = append(hostCfg.SecurityOpt, "seccomp="+profile)
+	}
+
+	if security.PidsLimit > 0 {
+		hostCfg.Resources.PidsLimit = security.PidsLimit
+	}
+
+	// The Docker API version used has no masked paths, so they are masked the
+	// same way Docker does it: /dev/null for files and an empty read-only
+	// tmpfs for directories
+	for _, path := range security.MaskedPaths {
+		if strings.HasSuffix(path, "/") {
+			if hostCfg.Tmpfs == nil {
+				hostCfg.Tmpfs = map[string]string{}
+			}
+			hostCfg.Tmpfs[strings.TrimSuffix(path, "/")] = "ro,size=0"
+			continue
+		}
+		hostCfg.Mounts = append(hostCfg.Mounts, mount.Mount{
+			Type:     mount.TypeBind,
+			Source:   "/dev/null",
+			Target:   path,
+			ReadOnly: true,
+		})
+	}
+
+	return nil
+}
+
+// formatSecurityOpts format the security options, seccomp profiles are
+// shortened to their hash
+func formatSecurityOpts(opts []string) string {
+	out := []string{}
+	for _, opt := range opts {
+		if strings.HasPrefix(opt, "seccomp=") && opt != "seccomp=unconfined" {
+			opt = fmt.Sprintf("seccomp=sha256:%.12x", sha256.Sum256([]byte(strings.TrimPrefix(opt, "seccomp="))))
+		}
+		out = append(out, opt)
+	}
+	return formatList(out)
+}
+
+func formatTmpfs(tmpfs map[string]string) string {
+	out := []string{}
+	for path, opts := range tmpfs {
+		out = append(out, path+":"+opts)
+	}
+	return formatList(out)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+func TestDesiredSpecSecurity(t *testing.T) {
+	spec, err := DesiredSpec(testServerCfg())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if formatList(spec.HostConfig.CapAdd) != "SYS_PTRACE" || len(spec.HostConfig.CapDrop) != 0 || spec.HostConfig.ReadonlyRootfs {
+		t.Fatalf("expected default security, got %+v", spec.HostConfig)
+	}
+
+	profile := filepath.Join(t.TempDir(), "seccomp.json")
+	if err := ioutil.WriteFile(profile, []byte(`{"defaultAction":"SCMP_ACT_ERRNO"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := testServerCfg()
+	cfg.Docker.Security = &config.Security{
+		DropAllCapabilities: true,
+		Capabilities:        []string{},
+		ReadOnlyRootfs:      true,
+		TmpfsSize:           "64m",
+		NoNewPrivileges:     true,
+		SeccompProfile:      profile,
+		PidsLimit:           256,
+		MaskedPaths:         []string{"/etc/shadow", "/sys/firmware/"},
+	}
+	secured, err := DesiredSpec(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostCfg := secured.HostConfig
+	if len(hostCfg.CapAdd) != 0 || formatList(hostCfg.CapDrop) != "ALL" {
+		t.Errorf("expected all capabilities dropped, got add %v drop %v", hostCfg.CapAdd, hostCfg.CapDrop)
+	}
+	if !hostCfg.ReadonlyRootfs || hostCfg.Tmpfs["/tmp"] != "rw,nosuid,nodev,size=64m" || hostCfg.Tmpfs["/sys/firmware"] != "ro,size=0" {
+		t.Errorf("expected read-only rootfs with tmpfs, got %v %v", hostCfg.ReadonlyRootfs, hostCfg.Tmpfs)
+	}
+	if len(hostCfg.SecurityOpt) != 2 || hostCfg.SecurityOpt[0] != "no-new-privileges" || hostCfg.SecurityOpt[1] != `seccomp={"defaultAction":"SCMP_ACT_ERRNO"}` {
+		t.Errorf("unexpected security opts %v", hostCfg.SecurityOpt)
+	}
+	if hostCfg.PidsLimit != 256 {
+		t.Errorf("expected pids limit 256, got %d", hostCfg.PidsLimit)
+	}
+	last := hostCfg.Mounts[len(hostCfg.Mounts)-1]
+	if last.Source != "/dev/null" || last.Target != "/etc/shadow" || !last.ReadOnly {
+		t.Errorf("expected /etc/shadow to be masked, got %+v", last)
+	}
+
+	changes := compareSpec(secured, spec.Config, spec.HostConfig, nil)
+	fields := map[string]bool{}
+	for _, change := range changes {
+		fields[change.Field] = true
+	}
+	for _, field := range []string{"capAdd", "capDrop", "readonlyRootfs", "securityOpt", "tmpfs", "mount", "resources.PidsLimit"} {
+		if !fields[field] {
+			t.Errorf("expected %s change, got %+v", field, changes)
+		}
+	}
+
+	cfg.Docker.Security.SeccompProfile = filepath.Join(t.TempDir(), "missing.json")
+	if _, err := DesiredSpec(cfg); err == nil {
+		t.Error("expected error for missing seccomp profile")
+	}
+}
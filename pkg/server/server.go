@@ -18,6 +18,7 @@ package server
 
 import (
 	"context"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
@@ -42,3 +43,21 @@ func GetServerContainer(serverCfg *config.Config) (types.ContainerJSON, error) {
 
 	return cont, nil
 }
+
+// StartedAt return the time the server container has been started at, a zero
+// time is returned when the container doesn't exist or isn't running
+func StartedAt(serverCfg *config.Config) (time.Time, error) {
+	cont, err := GetServerContainer(serverCfg)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+
+	if cont.State == nil || !cont.State.Running {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339Nano, cont.State.StartedAt)
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-units"
+	"github.com/galexrt/srcds_controller/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// SpecHashLabel label of the server container with the hash of the container
+// spec it has been created from
+const SpecHashLabel = "srcds_controller.spec-hash"
+
+// ContainerSpec desired spec of a server container computed from the config
+type ContainerSpec struct {
+	Config           *container.Config
+	HostConfig       *container.HostConfig
+	NetworkingConfig *network.NetworkingConfig
+}
+
+// DesiredSpec compute the container spec of the server from its config, the
+// spec hash label is set on the returned spec
+func DesiredSpec(serverCfg *config.Config) (*ContainerSpec, error) {
+	serverDir := serverCfg.Server.Path
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	contCfg := &container.Config{
+		Labels: map[string]string{
+			"app":        "gameserver",
+			"managed-by": "srcds_controller",
+		},
+		Env: []string{
+			"GOPS_CONFIG_DIR=/tmp/agent",
+		},
+		AttachStdin: false,
+		Tty:         false,
+		OpenStdin:   false,
+		Hostname:    hostname,
+		User:        fmt.Sprintf("%d:%d", serverCfg.Server.RunOptions.UID, serverCfg.Server.RunOptions.GID),
+		Image:       *serverCfg.Docker.Image,
+		WorkingDir:  serverDir,
+	}
+	contCfg.Env = append(contCfg.Env, serverCfg.Docker.AdditionalEnvVars...)
+	contCfg.Healthcheck = healthConfig(serverCfg.Docker.HealthCheck)
+
+	contHostCfg := &container.HostConfig{
+		RestartPolicy: container.RestartPolicy{
+			Name: "unless-stopped",
+		},
+		Mounts: []mount.Mount{
+			{
+				Type:     mount.TypeBind,
+				Source:   serverCfg.Docker.LocalTimeFile,
+				Target:   "/etc/localtime",
+				ReadOnly: true,
+			},
+			{
+				Type:     mount.TypeBind,
+				Source:   serverCfg.Docker.TimezoneFile,
+				Target:   "/etc/timezone",
+				ReadOnly: true,
+			},
+			{
+				Type:     mount.TypeBind,
+				Source:   "/etc/passwd",
+				Target:   "/etc/passwd",
+				ReadOnly: true,
+			},
+			{
+				Type:     mount.TypeBind,
+				Source:   "/etc/group",
+				Target:   "/etc/group",
+				ReadOnly: true,
+			},
+			// Server directory
+			{
+				Type:     mount.TypeBind,
+				Source:   serverDir,
+				Target:   serverDir,
+				ReadOnly: false,
+			},
+		},
+		NetworkMode: container.NetworkMode(serverCfg.Server.Network.DockerNetworkMode()),
+	}
+	if serverCfg.Server.Network.Published() {
+		contCfg.ExposedPorts, contHostCfg.PortBindings = portBindings(serverCfg)
+	}
+
+	if serverCfg.Server.MountsDir != "" {
+		contHostCfg.Mounts = append(contHostCfg.Mounts, mount.Mount{
+			Type:     mount.TypeBind,
+			Source:   serverCfg.Server.MountsDir,
+			Target:   serverCfg.Server.MountsDir,
+			ReadOnly: true,
+		})
+	}
+
+	// Add additional mounts
+	for _, additionalMount := range serverCfg.Docker.AdditionalMounts {
+		aMount := strings.Split(additionalMount, ":")
+		if len(aMount) < 2 {
+			log.Errorf("failed to add additional mount '%s' to server %s ...", serverCfg.Docker.AdditionalMounts, serverCfg.Server.Name)
+			continue
+		}
+		var readOnly bool
+		if len(aMount) == 3 && aMount[2] == "ro" {
+			readOnly = true
+		}
+
+		contHostCfg.Mounts = append(contHostCfg.Mounts, mount.Mount{
+			Type:     mount.TypeBind,
+			Source:   aMount[0],
+			Target:   aMount[1],
+			ReadOnly: readOnly,
+		})
+	}
+
+	if serverCfg.Server.Resources != nil {
+		contHostCfg.Resources = *serverCfg.Server.Resources
+	}
+
+	if err := applySecurity(serverCfg.Docker.Security, contHostCfg); err != nil {
+		return nil, err
+	}
+
+	// Disable Core dumps for the containers. GMod and other games seem to
+	// do core dumps for random reasons but we don't need them
+	contHostCfg.Ulimits = []*units.Ulimit{
+		{
+			Name: "core",
+			Hard: 10000,
+			Soft: 10000,
+		},
+	}
+
+	spec := &ContainerSpec{
+		Config:           contCfg,
+		HostConfig:       contHostCfg,
+		NetworkingConfig: &network.NetworkingConfig{},
+	}
+	hash, err := spec.Hash()
+	if err != nil {
+		return nil, err
+	}
+	spec.Config.Labels[SpecHashLabel] = hash
+
+	return spec, nil
+}
+
+// healthConfig return the Docker HEALTHCHECK running the runner healthcheck
+// subcommand in the server directory (the working dir)
+func healthConfig(healthCheck *config.HealthCheck) *container.HealthConfig {
+	if healthCheck == nil {
+		return nil
+	}
+	if healthCheck.Disabled {
+		return &container.HealthConfig{
+			Test: []string{"NONE"},
+		}
+	}
+
+	// The probe has to answer before Docker's timeout
+	probeTimeout := healthCheck.Timeout - 2*time.Second
+	if probeTimeout <= 0 {
+		probeTimeout = healthCheck.Timeout / 2
+	}
+	return &container.HealthConfig{
+		Test: []string{
+			"CMD",
+			"/bin/srcds_runner",
+			"healthcheck",
+			fmt.Sprintf("--timeout=%s", probeTimeout),
+			fmt.Sprintf("--start-period=%s", healthCheck.StartPeriod),
+		},
+		Interval: healthCheck.Interval,
+		Timeout:  healthCheck.Timeout,
+		Retries:  healthCheck.Retries,
+	}
+}
+
+// Hash return the hash of the spec, the spec hash label itself is ignored
+func (s *ContainerSpec) Hash() (string, error) {
+	labels := s.Config.Labels
+	cfg := *s.Config
+	cfg.Labels = map[string]string{}
+	for k, v := range labels {
+		if k != SpecHashLabel {
+			cfg.Labels[k] = v
+		}
+	}
+
+	out, err := json.Marshal(&ContainerSpec{
+		Config:           &cfg,
+		HostConfig:       s.HostConfig,
+		NetworkingConfig: s.NetworkingConfig,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal container spec. %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(out)), nil
+}
@@ -21,17 +21,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
-	"github.com/docker/docker/api/types/mount"
-	"github.com/docker/docker/api/types/network"
-	"github.com/docker/docker/api/types/strslice"
 	"github.com/docker/docker/client"
-	"github.com/docker/go-units"
 	"github.com/galexrt/srcds_controller/pkg/config"
 	"github.com/galexrt/srcds_controller/pkg/util"
 	log "github.com/sirupsen/logrus"
@@ -73,124 +67,30 @@ func Start(serverCfg *config.Config) error {
 		log.Infof("gameserver container image pull command output: %s", string(out))
 	}
 
+	spec, err := DesiredSpec(serverCfg)
+	if err != nil {
+		return err
+	}
+
+	// Recreate the stopped container when it doesn't match the config anymore
+	if cont.ContainerJSONBase != nil && cont.Config != nil && cont.Config.Labels[SpecHashLabel] != spec.Config.Labels[SpecHashLabel] {
+		if serverCfg.Docker.RecreateOnDrift {
+			log.Infof("server %s container doesn't match the config, recreating it", serverCfg.Server.Name)
+			if err := DockerCli.ContainerRemove(context.Background(), cont.ID, types.ContainerRemoveOptions{}); err != nil {
+				return fmt.Errorf("failed to remove drifted server %s container. %w", serverCfg.Server.Name, err)
+			}
+			cont = types.ContainerJSON{}
+		} else {
+			log.Warnf("server %s container doesn't match the config, run `sc diff %s` to see the differences", serverCfg.Server.Name, serverCfg.Server.Name)
+		}
+	}
+
 	// Start or create container
 	var containerID string
 	if cont.ContainerJSONBase != nil && (cont.State.Status == "created" || cont.State.Status == "exited") {
 		containerID = cont.ID
 	} else {
-		serverDir := serverCfg.Server.Path
-
-		hostname, err := os.Hostname()
-		if err != nil {
-			return err
-		}
-
-		contCfg := &container.Config{
-			Labels: map[string]string{
-				"app":        "gameserver",
-				"managed-by": "srcds_controller",
-			},
-			Env: []string{
-				"GOPS_CONFIG_DIR=/tmp/agent",
-			},
-			AttachStdin: false,
-			Tty:         false,
-			OpenStdin:   false,
-			Hostname:    hostname,
-			User:        fmt.Sprintf("%d:%d", serverCfg.Server.RunOptions.UID, serverCfg.Server.RunOptions.GID),
-			Image:       *serverCfg.Docker.Image,
-			WorkingDir:  serverDir,
-		}
-		contCfg.Env = append(contCfg.Env, serverCfg.Docker.AdditionalEnvVars...)
-
-		contHostCfg := &container.HostConfig{
-			RestartPolicy: container.RestartPolicy{
-				Name: "unless-stopped",
-			},
-			Mounts: []mount.Mount{
-				{
-					Type:     mount.TypeBind,
-					Source:   serverCfg.Docker.LocalTimeFile,
-					Target:   "/etc/localtime",
-					ReadOnly: true,
-				},
-				{
-					Type:     mount.TypeBind,
-					Source:   serverCfg.Docker.TimezoneFile,
-					Target:   "/etc/timezone",
-					ReadOnly: true,
-				},
-				{
-					Type:     mount.TypeBind,
-					Source:   "/etc/passwd",
-					Target:   "/etc/passwd",
-					ReadOnly: true,
-				},
-				{
-					Type:     mount.TypeBind,
-					Source:   "/etc/group",
-					Target:   "/etc/group",
-					ReadOnly: true,
-				},
-				// Server directory
-				{
-					Type:     mount.TypeBind,
-					Source:   serverDir,
-					Target:   serverDir,
-					ReadOnly: false,
-				},
-			},
-			NetworkMode: "host",
-			CapAdd: strslice.StrSlice{
-				"SYS_PTRACE",
-			},
-		}
-		if serverCfg.Server.MountsDir != "" {
-			contHostCfg.Mounts = append(contHostCfg.Mounts, mount.Mount{
-				Type:     mount.TypeBind,
-				Source:   serverCfg.Server.MountsDir,
-				Target:   serverCfg.Server.MountsDir,
-				ReadOnly: true,
-			})
-		}
-
-		// Add additional mounts
-		for _, additionalMount := range serverCfg.Docker.AdditionalMounts {
-			aMount := strings.Split(additionalMount, ":")
-			if len(aMount) < 2 {
-				log.Errorf("failed to add additional mount '%s' to server %s ...", serverCfg.Docker.AdditionalMounts, serverCfg.Server.Name)
-				continue
-			}
-			var readOnly bool
-			if len(aMount) == 3 && aMount[2] == "ro" {
-				readOnly = true
-			}
-
-			contHostCfg.Mounts = append(contHostCfg.Mounts, mount.Mount{
-				Type:     mount.TypeBind,
-				Source:   aMount[0],
-				Target:   aMount[1],
-				ReadOnly: readOnly,
-			})
-		}
-
-		if serverCfg.Server.Resources != nil {
-			contHostCfg.Resources = *serverCfg.Server.Resources
-		}
-
-		// Disable Core dumps for the containers. GMod and other games seem to
-		// do core dumps for random reasons but we don't need them
-		contHostCfg.Ulimits = []*units.Ulimit{
-			{
-				Name: "core",
-				Hard: 10000,
-				Soft: 10000,
-			},
-		}
-
-		netCfg := &network.NetworkingConfig{}
-		var resp container.ContainerCreateCreatedBody
-		resp, err = DockerCli.ContainerCreate(context.Background(), contCfg, contHostCfg, netCfg, util.GetContainerName(serverCfg.Docker.NamePrefix, serverCfg.Server.Name))
+		resp, err := DockerCli.ContainerCreate(context.Background(), spec.Config, spec.HostConfig, spec.NetworkingConfig, util.GetContainerName(serverCfg.Docker.NamePrefix, serverCfg.Server.Name))
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,261 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statuspage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// IncidentStatus Cachet incident status
+type IncidentStatus int
+
+const (
+	// IncidentInvestigating the cause of the incident is being investigated
+	IncidentInvestigating IncidentStatus = 1
+	// IncidentIdentified the cause of the incident has been identified
+	IncidentIdentified IncidentStatus = 2
+	// IncidentWatching a fix is in place and being watched
+	IncidentWatching IncidentStatus = 3
+	// IncidentFixed the incident has been resolved
+	IncidentFixed IncidentStatus = 4
+)
+
+// ComponentStatus Cachet component status
+type ComponentStatus int
+
+const (
+	// ComponentOperational component is operational
+	ComponentOperational ComponentStatus = 1
+	// ComponentPerformanceIssues component has performance issues
+	ComponentPerformanceIssues ComponentStatus = 2
+	// ComponentPartialOutage component is partially unavailable
+	ComponentPartialOutage ComponentStatus = 3
+	// ComponentMajorOutage component is unavailable
+	ComponentMajorOutage ComponentStatus = 4
+)
+
+// Incident a Cachet incident
+type Incident struct {
+	ID          int            `json:"id"`
+	Name        string         `json:"name"`
+	Message     string         `json:"message"`
+	Status      IncidentStatus `json:"status"`
+	ComponentID int            `json:"component_id"`
+}
+
+// Client client for a Cachet compatible status page API
+type Client struct {
+	cfg   *config.StatusPage
+	httpc *http.Client
+}
+
+// NewClient return a new status page client for the config of the server,
+// nil is returned when no status page is configured
+func NewClient(serverCfg *config.Config) *Client {
+	if !serverCfg.StatusPage.Enabled() {
+		return nil
+	}
+	return &Client{
+		cfg: serverCfg.StatusPage,
+		httpc: &http.Client{
+			Timeout: serverCfg.StatusPage.Timeout,
+		},
+	}
+}
+
+// Open open an incident for the component of the server and set the
+// component status, the ID of the incident is returned. When one of the given
+// (previously opened) incidents is still open, an update is added to it
+// instead.
+func (c *Client) Open(ctx context.Context, name string, message string, status IncidentStatus, componentStatus ComponentStatus, ids []int) (int, error) {
+	open, err := c.OpenIncidents(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, incident := range open {
+		if !containsID(ids, incident.ID) {
+			continue
+		}
+		if err := c.AddUpdate(ctx, incident.ID, message, status); err != nil {
+			return 0, err
+		}
+		return incident.ID, c.SetComponentStatus(ctx, componentStatus)
+	}
+
+	incident := &Incident{}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/incidents", map[string]interface{}{
+		"name":             name,
+		"message":          message,
+		"status":           status,
+		"visible":          1,
+		"component_id":     c.cfg.ComponentID,
+		"component_status": componentStatus,
+	}, incident); err != nil {
+		return 0, err
+	}
+	return incident.ID, nil
+}
+
+// Resolve resolve the given incidents of the component of the server, the
+// component is set operational again when no other incident is open for it
+func (c *Client) Resolve(ctx context.Context, message string, ids []int) error {
+	open, err := c.OpenIncidents(ctx)
+	if err != nil {
+		return err
+	}
+	others := 0
+	for _, incident := range open {
+		if !containsID(ids, incident.ID) {
+			others++
+			continue
+		}
+		if err := c.AddUpdate(ctx, incident.ID, message, IncidentFixed); err != nil {
+			return err
+		}
+	}
+	if others > 0 {
+		return nil
+	}
+	return c.SetComponentStatus(ctx, ComponentOperational)
+}
+
+// AddUpdate add an update to the incident
+func (c *Client) AddUpdate(ctx context.Context, incidentID int, message string, status IncidentStatus) error {
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/api/v1/incidents/%d/updates", incidentID), map[string]interface{}{
+		"message": message,
+		"status":  status,
+	}, nil)
+}
+
+// SetComponentStatus set the status of the component of the server
+func (c *Client) SetComponentStatus(ctx context.Context, status ComponentStatus) error {
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("/api/v1/components/%d", c.cfg.ComponentID), map[string]interface{}{
+		"status": status,
+	}, nil)
+}
+
+// OpenIncidents return the not yet fixed incidents of the component of the server
+func (c *Client) OpenIncidents(ctx context.Context) ([]*Incident, error) {
+	query := url.Values{}
+	query.Set("component_id", strconv.Itoa(c.cfg.ComponentID))
+	query.Set("sort", "id")
+	query.Set("order", "desc")
+
+	incidents := []*Incident{}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/incidents?"+query.Encode(), nil, &incidents); err != nil {
+		return nil, err
+	}
+
+	open := []*Incident{}
+	for _, incident := range incidents {
+		if incident.ComponentID == c.cfg.ComponentID && incident.Status != IncidentFixed {
+			open = append(open, incident)
+		}
+	}
+	return open, nil
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, in interface{}, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.cfg.URL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Cachet-Token", c.cfg.Token)
+
+	resp, err := c.httpc.Do(req)
+	if err != nil {
+		return fmt.Errorf("error during request to status page API. %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read status page API response body. %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status page API returned status %d (response body: %s)", resp.StatusCode, strings.ReplaceAll(string(respBody), "\n", "\\n"))
+	}
+
+	if out == nil {
+		return nil
+	}
+	// Cachet wraps all responses in a `data` object
+	data := struct {
+		Data interface{} `json:"data"`
+	}{
+		Data: out,
+	}
+	return json.Unmarshal(respBody, &data)
+}
+
+// OpenIncident open an incident for the server if a status page is
+// configured, or update one of the given incidents when still open. The ID of
+// the incident is returned, 0 when no incident has been opened (errors are
+// logged).
+func OpenIncident(serverCfg *config.Config, message string, status IncidentStatus, componentStatus ComponentStatus, ids []int) int {
+	client := NewClient(serverCfg)
+	if client == nil {
+		return 0
+	}
+	id, err := client.Open(context.Background(), serverCfg.Server.Name, message, status, componentStatus, ids)
+	if err != nil {
+		log.WithField("server", serverCfg.Server.Name).Errorf("failed to open status page incident. %+v", err)
+		return 0
+	}
+	return id
+}
+
+// ResolveIncidents resolve the given incidents of the server if a status page
+// is configured, errors are logged
+func ResolveIncidents(serverCfg *config.Config, message string, ids []int) {
+	client := NewClient(serverCfg)
+	if client == nil {
+		return
+	}
+	if err := client.Resolve(context.Background(), message, ids); err != nil {
+		log.WithField("server", serverCfg.Server.Name).Errorf("failed to resolve status page incidents. %+v", err)
+	}
+}
+
+func containsID(ids []int, id int) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statuspage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/galexrt/srcds_controller/pkg/config"
+)
+
+// fakeCachet minimal Cachet API keeping the incidents in memory
+type fakeCachet struct {
+	sync.Mutex
+	incidents       []*Incident
+	updates         int
+	componentStatus ComponentStatus
+}
+
+func (f *fakeCachet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	if r.Header.Get("X-Cachet-Token") != "token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	body := map[string]interface{}{}
+	if r.Method != http.MethodGet {
+		json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v1/incidents":
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": f.incidents})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/incidents":
+		f.incidents = append(f.incidents, &Incident{
+			ID:          len(f.incidents) + 1,
+			Name:        body["name"].(string),
+			Message:     body["message"].(string),
+			Status:      IncidentStatus(body["status"].(float64)),
+			ComponentID: int(body["component_id"].(float64)),
+		})
+		f.componentStatus = ComponentStatus(body["component_status"].(float64))
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": f.incidents[len(f.incidents)-1]})
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/v1/incidents/") && strings.HasSuffix(r.URL.Path, "/updates"):
+		id, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/incidents/"), "/updates"))
+		if err != nil || id < 1 || id > len(f.incidents) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.updates++
+		f.incidents[id-1].Status = IncidentStatus(body["status"].(float64))
+		w.Write([]byte(`{"data": {}}`))
+	case r.Method == http.MethodPut && r.URL.Path == "/api/v1/components/7":
+		f.componentStatus = ComponentStatus(body["status"].(float64))
+		w.Write([]byte(`{"data": {}}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestIncidentLifecycle(t *testing.T) {
+	cachet := &fakeCachet{}
+	srv := httptest.NewServer(cachet)
+	defer srv.Close()
+
+	client := NewClient(&config.Config{
+		Server: &config.Server{
+			Name: "server1",
+		},
+		StatusPage: &config.StatusPage{
+			URL:         srv.URL + "/",
+			Token:       "token",
+			ComponentID: 7,
+		},
+	})
+	if client == nil {
+		t.Fatal("expected status page client")
+	}
+
+	ctx := context.Background()
+	id, err := client.Open(ctx, "server1", "server died", IncidentIdentified, ComponentMajorOutage, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The incident opened before is updated when it is still open
+	if updated, err := client.Open(ctx, "server1", "server restarting", IncidentWatching, ComponentPartialOutage, []int{id}); err != nil || updated != id {
+		t.Fatalf("expected incident %d to be updated, got %d (err: %v)", id, updated, err)
+	}
+	if len(cachet.incidents) != 1 || cachet.updates != 1 || cachet.componentStatus != ComponentPartialOutage {
+		t.Fatalf("expected 1 incident with 1 update and partial outage, got %d incidents, %d updates, component status %d", len(cachet.incidents), cachet.updates, cachet.componentStatus)
+	}
+
+	// An incident opened by someone else is not resolved
+	manual, err := client.Open(ctx, "maintenance", "hardware maintenance", IncidentIdentified, ComponentMajorOutage, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Resolve(ctx, "server healthy", []int{id}); err != nil {
+		t.Fatal(err)
+	}
+	if cachet.incidents[id-1].Status != IncidentFixed || cachet.incidents[manual-1].Status == IncidentFixed || cachet.componentStatus != ComponentMajorOutage {
+		t.Errorf("expected only incident %d fixed and the component status kept, got incident statuses %d / %d, component status %d", id, cachet.incidents[id-1].Status, cachet.incidents[manual-1].Status, cachet.componentStatus)
+	}
+
+	open, err := client.OpenIncidents(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 1 || open[0].ID != manual {
+		t.Errorf("expected only incident %d to be open, got %+v", manual, open)
+	}
+
+	if err := client.Resolve(ctx, "maintenance done", []int{manual}); err != nil {
+		t.Fatal(err)
+	}
+	if cachet.componentStatus != ComponentOperational {
+		t.Errorf("expected component operational once all incidents are resolved, got %d", cachet.componentStatus)
+	}
+}
+
+func TestNewClientDisabled(t *testing.T) {
+	if NewClient(&config.Config{Server: &config.Server{Name: "server1"}}) != nil {
+		t.Error("expected no client without status page config")
+	}
+}
@@ -17,12 +17,14 @@ limitations under the License.
 package userconfig
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
 	"sync"
 
+	"github.com/galexrt/srcds_controller/pkg/checks"
 	"github.com/galexrt/srcds_controller/pkg/config"
 	"github.com/imdario/mergo"
 	log "github.com/sirupsen/logrus"
@@ -45,6 +47,68 @@ type Config struct {
 	Servers map[string]*config.Config
 }
 
+// GetServer return the server config by name
+func (c *Config) GetServer(name string) (*config.Config, bool) {
+	c.Lock()
+	defer c.Unlock()
+	srv, ok := c.Servers[name]
+	return srv, ok
+}
+
+// GetServers return a copy of the server configs map
+func (c *Config) GetServers() map[string]*config.Config {
+	c.Lock()
+	defer c.Unlock()
+	servers := make(map[string]*config.Config, len(c.Servers))
+	for name, srv := range c.Servers {
+		servers[name] = srv
+	}
+	return servers
+}
+
+// SetServers replace the server configs
+func (c *Config) SetServers(servers map[string]*config.Config) {
+	c.Lock()
+	defer c.Unlock()
+	c.Servers = servers
+}
+
+// LoadFiles load the global config file and the user config file with the
+// server configs it points to
+func LoadFiles(cfgFile string, globalCfgFile string) (*UserConfig, *Config, error) {
+	globalCfg := &config.GlobalConfig{}
+	if _, err := os.Stat(globalCfgFile); err == nil {
+		out, err := ioutil.ReadFile(globalCfgFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err = yaml.Unmarshal(out, globalCfg); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	userCfg := &UserConfig{}
+	cfgs := &Config{
+		Servers: map[string]*config.Config{},
+	}
+
+	if _, err := os.Stat(cfgFile); err != nil {
+		return nil, nil, fmt.Errorf("no config found in home dir nor specified by flag")
+	}
+	out, err := ioutil.ReadFile(cfgFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = yaml.Unmarshal(out, userCfg); err != nil {
+		return nil, nil, err
+	}
+	if err = userCfg.Load(globalCfg, cfgs); err != nil {
+		return nil, nil, err
+	}
+
+	return userCfg, cfgs, nil
+}
+
 // Load load the configs into a Config object
 func (uc *UserConfig) Load(globalCfg *config.GlobalConfig, cfgs *Config) error {
 	configsToLoad := []string{}
@@ -86,6 +150,9 @@ func (uc *UserConfig) Load(globalCfg *config.GlobalConfig, cfgs *Config) error {
 			if err = serverCfg.Verify(); err != nil {
 				return err
 			}
+			if err = checks.Validate(serverCfg); err != nil {
+				return err
+			}
 
 			cfgs.Servers[serverCfg.Server.Name] = serverCfg
 		} else {
@@ -129,5 +196,26 @@ func mergeGlobalWithServerCfg(globalCfg *config.GlobalConfig, cfg *config.Config
 		}
 	}
 
+	if cfg.StatusPage == nil {
+		if globalCfg.StatusPage != nil {
+			statusPage := *globalCfg.StatusPage
+			cfg.StatusPage = &statusPage
+		}
+	} else if globalCfg.StatusPage != nil {
+		if err := mergo.Merge(cfg.StatusPage, globalCfg.StatusPage); err != nil {
+			return err
+		}
+	}
+
+	// Server webhooks are added to the global webhooks
+	if globalCfg.Notifications != nil {
+		notifications := &config.Notifications{}
+		if cfg.Notifications != nil {
+			notifications.Webhooks = append(notifications.Webhooks, cfg.Notifications.Webhooks...)
+		}
+		notifications.Webhooks = append(notifications.Webhooks, globalCfg.Notifications.Webhooks...)
+		cfg.Notifications = notifications
+	}
+
 	return nil
 }
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+)
+
+// watchDebounce time to wait for further file events before reloading
+const watchDebounce = 2 * time.Second
+
+// Watch watch the config files and server configs for changes and
+// additionally rescan the server directory globs every rescan interval.
+// The reload func is called with the newly loaded config, failed loads are
+// logged and otherwise ignored.
+func Watch(cfgFile string, globalCfgFile string, rescanInterval time.Duration, stopCh <-chan struct{}, reload func(cfgs *Config)) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Errorf("error creating fsnotify watcher for configs, only rescanning periodically. %+v", err)
+	}
+	if watcher != nil {
+		defer watcher.Close()
+	}
+
+	watched := map[string]bool{}
+	updateWatches := func(userCfg *UserConfig, cfgs *Config) {
+		if watcher == nil {
+			return
+		}
+		paths := map[string]bool{
+			cfgFile:       true,
+			globalCfgFile: true,
+		}
+		if userCfg != nil {
+			for _, dir := range userCfg.ServerDirectories {
+				// Watch the parent of the glob to get notified of new server directories
+				paths[filepath.Dir(dir)] = true
+			}
+		}
+		if cfgs != nil {
+			for _, srv := range cfgs.GetServers() {
+				paths[path.Join(srv.Server.Path, ".srcds_controller_server.yaml")] = true
+			}
+		}
+
+		for p := range watched {
+			if !paths[p] {
+				watcher.Remove(p)
+				delete(watched, p)
+			}
+		}
+		for p := range paths {
+			if watched[p] {
+				continue
+			}
+			if _, err := os.Stat(p); err != nil {
+				continue
+			}
+			if err := watcher.Add(p); err != nil {
+				log.Warnf("failed to watch %s for config changes. %+v", p, err)
+				continue
+			}
+			watched[p] = true
+		}
+	}
+
+	load := func() {
+		userCfg, cfgs, err := LoadFiles(cfgFile, globalCfgFile)
+		if err != nil {
+			log.Errorf("failed to reload configs, keeping current config. %+v", err)
+			return
+		}
+		updateWatches(userCfg, cfgs)
+		reload(cfgs)
+	}
+
+	// Only setup the watches initially, the config has already been loaded
+	if userCfg, cfgs, err := LoadFiles(cfgFile, globalCfgFile); err == nil {
+		updateWatches(userCfg, cfgs)
+	}
+
+	var events chan fsnotify.Event
+	var errors chan error
+	if watcher != nil {
+		events = watcher.Events
+		errors = watcher.Errors
+	}
+
+	rescan := time.NewTicker(rescanInterval)
+	defer rescan.Stop()
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-rescan.C:
+			log.Debug("rescanning configs")
+			load()
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			log.Debugf("config watch event %s", event)
+			// Editors commonly replace files, the watch needs to be re-added
+			if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+				delete(watched, event.Name)
+			}
+			debounce = time.After(watchDebounce)
+		case <-debounce:
+			log.Info("configs have changed, reloading")
+			debounce = nil
+			load()
+		case err, ok := <-errors:
+			if !ok {
+				errors = nil
+				continue
+			}
+			log.Errorf("error during config fsnotify. %+v", err)
+		}
+	}
+}